@@ -0,0 +1,149 @@
+package database
+
+import (
+	"fmt"
+)
+
+// run a built single value select and scan its value into dest
+func (q *query) scanScalar(dest any) error {
+	q.queryString += ";"
+
+	db, err := q.runner()
+	if err != nil {
+		return err
+	}
+
+	args, err := bindParams(q.args, nil)
+	if err != nil {
+		return err
+	}
+
+	return db.QueryRowContext(q.context(), q.queryString, args...).Scan(dest)
+}
+
+// run an aggregate over a column of the matching rows, order and limits are dropped
+func (q *query) aggregate(fn, col string, dest any) error {
+	c := q.fresh()
+
+	if len(c.setOps) > 0 {
+		return fmt.Errorf("%s over a set operation not supported, select the aggregate instead", fn)
+	}
+
+	if c.isGrouped() {
+		return fmt.Errorf("%s over a grouped query not supported, select the aggregate instead", fn)
+	}
+
+	if c.isDistinct() {
+		return fmt.Errorf("%s over a distinct query not supported, select the aggregate instead", fn)
+	}
+
+	c.selects = []Expr{selectColumn(fmt.Sprintf("%s(%s)", fn, col))}
+	c.orderBy = nil
+	c.limit = nil
+	c.offset = nil
+	c.withTies = false
+	c.locks = nil
+
+	err := c.buildSelect()
+	if err != nil {
+		return err
+	}
+
+	return c.scanScalar(dest)
+}
+
+// count the rows the select query returns. Grouped queries count their groups
+// and limits apply like they do for Exists, use NoLimit and NoOffset to count
+// every matching row.
+func (q *query) Count() (int64, error) {
+	c := q.fresh()
+
+	err := c.buildCount()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	err = c.scanScalar(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// check if the select query returns any row
+func (q *query) Exists() (bool, error) {
+	c := q.fresh()
+	c.orderBy = nil
+
+	err := c.buildSelect()
+	if err != nil {
+		return false, err
+	}
+
+	c.queryString = fmt.Sprintf("SELECT EXISTS (%s)", c.queryString)
+
+	err = c.checkLockTx()
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = c.scanScalar(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// sum of the column over the matching rows scanned into dest, NULL when no
+// row matches. Sums of integer and numeric columns are numeric, scan them into
+// a string or a decimal type to keep their precision.
+//
+//	var total sql.NullString
+//	err := q.For("orders").Sum("total", &total)
+func (q *query) Sum(col string, dest any) error {
+	return q.aggregate("SUM", col, dest)
+}
+
+// average of the column over the matching rows scanned into dest, NULL when
+// no row matches
+func (q *query) Avg(col string, dest any) error {
+	return q.aggregate("AVG", col, dest)
+}
+
+// smallest value of the column scanned into dest, NULL when no row matches
+func (q *query) Min(col string, dest any) error {
+	return q.aggregate("MIN", col, dest)
+}
+
+// largest value of the column scanned into dest, NULL when no row matches
+func (q *query) Max(col string, dest any) error {
+	return q.aggregate("MAX", col, dest)
+}
+
+// values of a single column of the select query, in its order and limits,
+// typed like the values returned by Find
+func (q *query) Pluck(col string) ([]any, error) {
+	c := q.clone()
+	c.selects = []Expr{selectColumn(col)}
+
+	db, queryString, args, err := c.prepareSelect()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := queryRows(c.context(), db, queryString, args)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(rows))
+	for i, row := range rows {
+		values[i] = row[0]
+	}
+
+	return values, nil
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAggregates(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	orders := q.Select("_id", "total").For("orders").Where([]Condition{Eq("status", "paid")}).
+		OrderBy([]OrderClause{{Field: "total", Order: "DESC"}}).Limit(10)
+
+	// count applies the limits like exists
+	mock.ExpectQuery(`SELECT COUNT(*) FROM (SELECT _id, total FROM orders WHERE  status = $1 ORDER BY total DESC LIMIT $2) AS counted;`).WithArgs(`paid`, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(10)))
+
+	count, err := orders.Count()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), count)
+
+	mock.ExpectQuery(`SELECT COUNT(*) FROM orders WHERE  status = $1;`).WithArgs(`paid`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(42)))
+
+	count, err = orders.NoLimit().Count()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), count)
+
+	// exists keeps the limits, they decide if a row is returned
+	mock.ExpectQuery(`SELECT EXISTS (SELECT _id, total FROM orders WHERE  status = $1 LIMIT $2);`).WithArgs(`paid`, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	exists, err := orders.Exists()
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	// aggregates scan into the destination, numeric sums keep their precision as text
+	mock.ExpectQuery(`SELECT SUM(total) FROM orders WHERE  status = $1;`).WithArgs(`paid`).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow([]byte("90071992547409930.01")))
+
+	var sum sql.NullString
+	err = orders.Sum("total", &sum)
+	assert.Nil(t, err)
+	assert.Equal(t, sql.NullString{String: "90071992547409930.01", Valid: true}, sum)
+
+	mock.ExpectQuery(`SELECT AVG(total) FROM orders WHERE  status = $1;`).WithArgs(`paid`).
+		WillReturnRows(sqlmock.NewRows([]string{"avg"}).AddRow(nil))
+
+	var avg sql.NullFloat64
+	err = orders.Avg("total", &avg)
+	assert.Nil(t, err)
+	assert.False(t, avg.Valid)
+
+	// bytea values stay bytes
+	mock.ExpectQuery(`SELECT MIN(checksum) FROM orders WHERE  status = $1;`).WithArgs(`paid`).
+		WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow([]byte{0x00, 0xff}))
+
+	var first []byte
+	err = orders.Min("checksum", &first)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0xff}, first)
+
+	mock.ExpectQuery(`SELECT MAX(total) FROM orders WHERE  status = $1;`).WithArgs(`paid`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(int64(900)))
+
+	var last int64
+	err = orders.Max("total", &last)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(900), last)
+
+	// pluck keeps the order and limits
+	mock.ExpectQuery(`SELECT _id FROM orders WHERE  status = $1 ORDER BY total DESC LIMIT $2;`).WithArgs(`paid`, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow(int64(7)).AddRow(int64(3)))
+
+	ids, err := orders.Pluck("_id")
+	assert.Nil(t, err)
+	assert.Equal(t, []any{int64(7), int64(3)}, ids)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// failure cases
+	err = orders.GroupBy([]string{"status"}).Sum("total", &sum)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "grouped query")
+
+	err = orders.Sum("total; DROP TABLE orders", &sum)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// field = ANY($1), the values are bound as one array parameter so the list
+// may be larger than the bind parameter limit
+func EqAny(field string, values any) Condition {
+	return Condition{Field: field, Type: ConditionArray, Operator: "= ANY", Values: []any{values}}
+}
+
+// field <> ALL($1), the field matches none of the values
+func NeqAll(field string, values any) Condition {
+	return Condition{Field: field, Type: ConditionArray, Operator: "<> ALL", Values: []any{values}}
+}
+
+// field @> $1, the array column holds every value
+func ArrayContains(field string, values any) Condition {
+	return Condition{Field: field, Type: ConditionArray, Operator: "@>", Values: []any{values}}
+}
+
+// field <@ $1, every element of the array column is one of the values
+func ArrayContainedBy(field string, values any) Condition {
+	return Condition{Field: field, Type: ConditionArray, Operator: "<@", Values: []any{values}}
+}
+
+// field && $1, the array column holds any of the values
+func ArrayOverlaps(field string, values any) Condition {
+	return Condition{Field: field, Type: ConditionArray, Operator: "&&", Values: []any{values}}
+}
+
+// array containment operators
+var arrayOperators = map[string]bool{
+	"@>": true,
+	"<@": true,
+	"&&": true,
+}
+
+// bind a slice as a single array parameter, valuers such as pq.StringArray are kept
+func arrayValue(value any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("array condition requires a list of values")
+	case Param:
+		// named parameters are wrapped once their value is known
+		return encodedParam{name: v, encode: arrayValue}, nil
+	case driver.Valuer:
+		return value, nil
+	}
+	return pq.Array(value), nil
+}
+
+// build an array condition, e.g. _id = ANY($1) or tags && $1
+func (q *query) buildArrayClause(field string, cond Condition) (string, error) {
+	op := strings.ToUpper(strings.Join(strings.Fields(cond.Operator), " "))
+
+	var format string
+	if arrayOperators[op] {
+		format = "%s%s %s %s"
+	} else {
+		// comparison with any / all of the elements, e.g. = ANY or <> ALL
+		idx := strings.LastIndex(op, " ")
+		if idx == -1 {
+			return "", fmt.Errorf("invalid array operator %q", cond.Operator)
+		}
+
+		cmp, quantifier := op[:idx], op[idx+1:]
+		if quantifier != "ANY" && quantifier != "ALL" {
+			return "", fmt.Errorf("invalid array operator %q", cond.Operator)
+		}
+
+		cmp, err := normalizeOperator(cmp)
+		if err != nil || strings.HasPrefix(cmp, "IS") {
+			return "", fmt.Errorf("invalid array operator %q", cond.Operator)
+		}
+
+		op = cmp + " " + quantifier
+		format = "%s%s %s(%s)"
+	}
+
+	value, err := arrayValue(cond.Values[0])
+	if err != nil {
+		return "", err
+	}
+
+	ph, err := q.bindValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	notStr := ""
+	if cond.Not {
+		notStr = "NOT "
+	}
+
+	return fmt.Sprintf(format, notStr, field, op, ph), nil
+}
+
+// decode a one dimensional array column of a known element type into a go
+// slice. Other values, including multi dimensional arrays and arrays of
+// composite or geometric types, are returned as is.
+func decodeArray(typeName string, value any) any {
+	var dest sql.Scanner
+
+	switch typeName {
+	case "_INT2", "_INT4", "_INT8", "_OID":
+		dest = &pq.Int64Array{}
+	case "_FLOAT4", "_FLOAT8":
+		dest = &pq.Float64Array{}
+	case "_BOOL":
+		dest = &pq.BoolArray{}
+	case "_BYTEA":
+		dest = &pq.ByteaArray{}
+	case "_TEXT", "_VARCHAR", "_BPCHAR", "_NAME", "_UUID", "_NUMERIC", "_DATE",
+		"_TIME", "_TIMETZ", "_TIMESTAMP", "_TIMESTAMPTZ", "_INTERVAL", "_INET", "_CIDR":
+		// text like elements, numeric is kept as text to avoid losing precision
+		dest = &pq.StringArray{}
+	default:
+		return value
+	}
+
+	if err := dest.Scan(value); err != nil {
+		return value
+	}
+
+	switch a := dest.(type) {
+	case *pq.Int64Array:
+		return []int64(*a)
+	case *pq.Float64Array:
+		return []float64(*a)
+	case *pq.BoolArray:
+		return []bool(*a)
+	case *pq.ByteaArray:
+		return [][]byte(*a)
+	case *pq.StringArray:
+		return []string(*a)
+	}
+	return value
+}
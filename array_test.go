@@ -0,0 +1,132 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestArrayConditions(t *testing.T) {
+	ids := make([]int64, 20000)
+	for i := range ids {
+		ids[i] = int64(i)
+	}
+
+	// a single parameter whatever the number of ids
+	sqlStr, args, err := NewBuilder().Select("_id").For("people").Where([]Condition{
+		And(
+			EqAny("_id", ids),
+			NeqAll("location", []string{"FR", "CN"}),
+			ArrayContains("tags", []string{"oncall"}),
+			ArrayOverlaps("skills", pq.StringArray{"go", "sql"}),
+			ArrayContainedBy("roles", []string{"admin", "editor"}),
+			Condition{Field: "email", Type: ConditionArray, Operator: "not ilike any", Values: []any{[]string{"%@spam.io"}}},
+		),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people WHERE ( _id = ANY($1) AND location <> ALL($2) AND tags @> $3 AND skills && $4 AND roles <@ $5 AND email NOT ILIKE ANY($6) );`, sqlStr)
+	assert.Len(t, args, 6)
+	assert.Equal(t, pq.Array(ids), args[0])
+	assert.Equal(t, pq.StringArray{"go", "sql"}, args[3])
+
+	// failure cases
+	invalid := []Condition{
+		{Field: "_id", Type: ConditionArray, Operator: "= SOME", Values: []any{ids}},
+		{Field: "_id", Type: ConditionArray, Operator: "IS ANY", Values: []any{ids}},
+		{Field: "_id", Type: ConditionArray, Operator: "@@", Values: []any{ids}},
+		{Field: "_id", Type: ConditionArray, Operator: "= ANY", Values: []any{nil}},
+	}
+
+	for _, cond := range invalid {
+		_, _, err = NewBuilder().Select().For("people").Where([]Condition{cond}).ToSQL()
+		assert.NotNil(t, err, "%+v", cond)
+	}
+}
+
+// named parameters are wrapped as arrays when the plan runs
+func TestArrayPlanParams(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	plan, err := q.Select("_id").For("people").Where([]Condition{EqAny("_id", Param("ids"))}).Compile()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ids"}, plan.Params())
+
+	mock.ExpectQuery(`SELECT _id FROM people WHERE _id = ANY($1);`).WithArgs(`{4,7}`).
+		WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow(int64(4)).AddRow(int64(7)))
+
+	rows, err := plan.Find(map[string]any{"ids": []int64{4, 7}})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{int64(4)}, {int64(7)}}, rows)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	_, err = plan.Find(map[string]any{"ids": nil})
+	assert.NotNil(t, err)
+}
+
+func TestScanArrays(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	rows := mock.NewRowsWithColumnDefinition(
+		mock.NewColumn("_id").OfType("INT8", int64(0)),
+		mock.NewColumn("tags").OfType("_TEXT", ""),
+		mock.NewColumn("scores").OfType("_INT4", ""),
+		mock.NewColumn("flags").OfType("_BOOL", ""),
+		mock.NewColumn("weights").OfType("_FLOAT8", ""),
+	).AddRow(int64(1), []byte(`{oncall,"night shift"}`), []byte(`{3,5}`), []byte(`{t,f}`), []byte(`{0.5,1.25}`)).
+		AddRow(int64(2), nil, []byte(`{}`), nil, nil)
+
+	mock.ExpectQuery(`SELECT _id, tags, scores, flags, weights FROM people WHERE tags && $1;`).WithArgs(sqlmock.AnyArg()).WillReturnRows(rows)
+
+	result, err := q.Select("_id", "tags", "scores", "flags", "weights").For("people").Where([]Condition{
+		ArrayOverlaps("tags", []string{"oncall"}),
+	}).Find()
+
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{
+		{int64(1), []string{"oncall", "night shift"}, []int64{3, 5}, []bool{true, false}, []float64{0.5, 1.25}},
+		{int64(2), nil, []int64{}, nil, nil},
+	}, result)
+
+	// multi dimensional and geometric arrays are returned as is
+	rows = mock.NewRowsWithColumnDefinition(
+		mock.NewColumn("grid").OfType("_INT4", ""),
+		mock.NewColumn("labels").OfType("_TEXT", ""),
+		mock.NewColumn("areas").OfType("_BOX", ""),
+	).AddRow([]byte(`{{1,2},{3,4}}`), []byte(`{{a,b},{c,d}}`), []byte(`{(1,1),(0,0);(2,2),(1,1)}`))
+
+	mock.ExpectQuery(`SELECT grid, labels, areas FROM boards;`).WillReturnRows(rows)
+
+	result, err = q.Select("grid", "labels", "areas").For("boards").Find()
+
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{
+		{[]byte(`{{1,2},{3,4}}`), []byte(`{{a,b},{c,d}}`), []byte(`{(1,1),(0,0);(2,2),(1,1)}`)},
+	}, result)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
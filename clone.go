@@ -0,0 +1,43 @@
+package database
+
+import "slices"
+
+// copy the query, builder methods work on a copy so a query can be
+// reused and branched without affecting the original
+func (q *query) clone() *query {
+	c := *q
+	c.cols = slices.Clone(q.cols)
+	c.selects = slices.Clone(q.selects)
+	c.windows = slices.Clone(q.windows)
+	c.distinctOn = slices.Clone(q.distinctOn)
+	c.locks = slices.Clone(q.locks)
+	c.colValues = slices.Clone(q.colValues)
+	c.whereConds = slices.Clone(q.whereConds)
+	c.joins = slices.Clone(q.joins)
+	c.groupBy = slices.Clone(q.groupBy)
+	c.groupExprs = slices.Clone(q.groupExprs)
+	c.having = slices.Clone(q.having)
+	c.orderBy = slices.Clone(q.orderBy)
+	c.ctes = slices.Clone(q.ctes)
+	c.setOps = slices.Clone(q.setOps)
+	c.returning = slices.Clone(q.returning)
+	c.args = slices.Clone(q.args)
+	c.aliases = slices.Clone(q.aliases)
+	c.errors = slices.Clone(q.errors)
+	return &c
+}
+
+// copy of the query with an empty build state, builds run on a fresh copy
+// so they never leave placeholders or arguments behind
+func (q *query) fresh() *query {
+	c := q.clone()
+	c.args = nil
+	c.argCount = 0
+	c.queryString = ""
+	return c
+}
+
+// explicit copy of the query
+func (q *query) Clone() QueryExecutor {
+	return q.clone()
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestReusableQuery(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	base, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	people := base.Select("_id", "email").For("people").Where([]Condition{Eq("location", "FR")})
+
+	// executing the same builder twice sends the same statement
+	queryString1 := `SELECT _id, email FROM people WHERE  location = $1;`
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(queryString1).WithArgs(`FR`).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+		rows, err := people.Find()
+		assert.Nil(t, err)
+		assert.NotNil(t, rows)
+	}
+
+	// FindOne does not overwrite the where clause of the builder
+	mock.ExpectQuery(`SELECT _id, email FROM people WHERE  _id = $1;`).WithArgs(`15`).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"15"}...))
+
+	row, err := people.FindOne("15")
+	assert.Nil(t, err)
+	assert.NotNil(t, row)
+
+	mock.ExpectQuery(queryString1).WithArgs(`FR`).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+	rows, err := people.Find()
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestBranchedQueries(t *testing.T) {
+	base := NewBuilder().Select("_id").For("people")
+
+	night := base.Where([]Condition{Eq("shift_type", "nocturnal")})
+	day := base.Where([]Condition{Eq("shift_type", "diurnal")}).OrderBy([]OrderClause{{Field: "_id", Order: "ASC"}})
+
+	sqlStr, args, err := base.ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people;`, sqlStr)
+	assert.Empty(t, args)
+
+	sqlStr, args, err = night.ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people WHERE  shift_type = $1;`, sqlStr)
+	assert.Equal(t, []any{"nocturnal"}, args)
+
+	sqlStr, args, err = day.ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people WHERE  shift_type = $1 ORDER BY _id ASC;`, sqlStr)
+	assert.Equal(t, []any{"diurnal"}, args)
+
+	// errors of a branch do not leak into the base
+	_, _, err = base.For("").ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = base.Clone().ToSQL()
+	assert.Nil(t, err)
+}
+
+func TestSharedBaseQuery(t *testing.T) {
+	base := NewBuilder().Select("_id").For("people").Where([]Condition{Eq("location", "FR")})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			sqlStr, args, err := base.Limit(i + 1).ToSQL()
+			assert.Nil(t, err)
+			assert.Contains(t, sqlStr, `WHERE  location = $1 LIMIT $2`)
+			assert.Equal(t, []any{"FR", i + 1}, args)
+		}(i)
+	}
+	wg.Wait()
+}
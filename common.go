@@ -2,17 +2,47 @@ package database
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 )
 
+// bind a value as the next placeholder, column references are rendered as is
+func (q *query) bindValue(value any) (string, error) {
+	if col, ok := value.(ColumnRef); ok {
+		return q.fieldIdent(string(col))
+	}
+
+	q.argCount++
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%s", strconv.Itoa(q.argCount)), nil
+}
+
+// render the left hand side of a condition
+func (q *query) conditionField(cond Condition) (string, error) {
+	if cond.Expr != nil {
+		if cond.Field != "" {
+			return "", fmt.Errorf("condition cannot have both a field and an expression")
+		}
+		return cond.Expr.buildExpr(q)
+	}
+	return q.fieldIdent(cond.Field)
+}
+
 // build the in clause
-func (q *query) buildInClause(cond Condition) (string, int) {
-	values := cond.Values[0].([]interface{})
+func (q *query) buildInClause(field string, cond Condition) (string, error) {
+	values, ok := cond.Values[0].([]interface{})
+	if !ok || len(values) == 0 {
+		return "", fmt.Errorf("in condition for %s requires a list of values", field)
+	}
+
 	placeholders := make([]string, len(values))
-	for i := range values {
-		q.ArgCount++
-		placeholders[i] = fmt.Sprintf("$%s", strconv.Itoa(q.ArgCount))
+	for i, v := range values {
+		ph, err := q.bindValue(v)
+		if err != nil {
+			return "", err
+		}
+		placeholders[i] = ph
 	}
 
 	// check for not condition
@@ -21,23 +51,103 @@ func (q *query) buildInClause(cond Condition) (string, int) {
 		notStr = "NOT"
 	}
 
-	return fmt.Sprintf("%s %s IN (%s)", cond.Field, notStr, strings.Join(placeholders, ", ")), q.ArgCount
+	return fmt.Sprintf("%s %s IN (%s)", field, notStr, strings.Join(placeholders, ", ")), nil
+}
+
+// build a row comparison, e.g. (created_at, _id) > ($1, $2)
+func (q *query) buildTupleClause(cond Condition) (string, error) {
+	fields := strings.Split(cond.Field, ",")
+	if len(fields) != len(cond.Values) {
+		return "", fmt.Errorf("tuple condition for %s requires one value per column", cond.Field)
+	}
+
+	op, err := normalizeOperator(cond.Operator)
+	if err != nil {
+		return "", err
+	}
+
+	cols := make([]string, len(fields))
+	placeholders := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i], err = q.fieldIdent(f)
+		if err != nil {
+			return "", err
+		}
+
+		placeholders[i], err = q.bindValue(cond.Values[i])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	notStr := ""
+	if cond.Not {
+		notStr = "NOT "
+	}
+
+	return fmt.Sprintf("%s(%s) %s (%s)", notStr, strings.Join(cols, ", "), op, strings.Join(placeholders, ", ")), nil
 }
 
 // Recursive function to handle nested conditions
-func (q *query) buildWhereClauses(conds []Condition, whereClauses []string) ([]string, int) {
+func (q *query) buildWhereClauses(conds []Condition, whereClauses []string) ([]string, error) {
 
 	for i, cond := range conds {
 		if cond.Nested != nil {
+			if len(cond.Nested.Conditions) == 0 {
+				return nil, fmt.Errorf("empty condition group not allowed")
+			}
+
 			// Open parentheses for nested conditions
-			whereClauses = append(whereClauses, "(")
+			if cond.Not {
+				whereClauses = append(whereClauses, "NOT (")
+			} else {
+				whereClauses = append(whereClauses, "(")
+			}
 
-			wc, ac := q.buildWhereClauses(cond.Nested.Conditions, whereClauses)
+			wc, err := q.buildWhereClauses(cond.Nested.Conditions, whereClauses)
+			if err != nil {
+				return nil, err
+			}
 			whereClauses = wc
-			q.ArgCount = ac
 
 			whereClauses = append(whereClauses, ")")
+		} else if cond.Type == ConditionRaw {
+			if cond.Expr == nil {
+				return nil, fmt.Errorf("raw condition missing sql")
+			}
+
+			raw, err := cond.Expr.buildExpr(q)
+			if err != nil {
+				return nil, err
+			}
+
+			// parenthesized so the fragment keeps its precedence
+			notStr := ""
+			if cond.Not {
+				notStr = "NOT "
+			}
+			whereClauses = append(whereClauses, fmt.Sprintf("%s(%s)", notStr, raw))
+		} else if cond.Type == ConditionTextSearch {
+			searchClause, err := q.buildTextSearchClause(cond)
+			if err != nil {
+				return nil, err
+			}
+			whereClauses = append(whereClauses, searchClause)
+		} else if cond.Type == ConditionTuple {
+			tupleClause, err := q.buildTupleClause(cond)
+			if err != nil {
+				return nil, err
+			}
+			whereClauses = append(whereClauses, tupleClause)
 		} else {
+			field, err := q.conditionField(cond)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(cond.Values) == 0 {
+				return nil, fmt.Errorf("condition for %s missing value", field)
+			}
 
 			// check for the not condition
 			notStr := ""
@@ -48,40 +158,88 @@ func (q *query) buildWhereClauses(conds []Condition, whereClauses []string) ([]s
 			// Add the actual condition
 			switch cond.Type {
 			case ConditionIn: // handle in clause
-				inClause, iargc := q.buildInClause(cond)
-				q.ArgCount = iargc
+				inClause, err := q.buildInClause(field, cond)
+				if err != nil {
+					return nil, err
+				}
 				whereClauses = append(whereClauses, inClause)
-				q.Args = append(q.Args, cond.Values[0].([]interface{})...)
 			case ConditionBetween: // handle between clause
-				q.ArgCount++
-				arg1 := q.ArgCount
-				q.ArgCount++
-				arg2 := q.ArgCount
-				betweenClause := fmt.Sprintf("%s %s BETWEEN $%s AND $%s", cond.Field, notStr, strconv.Itoa(arg1), strconv.Itoa(arg2))
+				if len(cond.Values) < 2 {
+					return nil, fmt.Errorf("between condition for %s requires two values", field)
+				}
+
+				arg1, err := q.bindValue(cond.Values[0])
+				if err != nil {
+					return nil, err
+				}
+				arg2, err := q.bindValue(cond.Values[1])
+				if err != nil {
+					return nil, err
+				}
+				betweenClause := fmt.Sprintf("%s %s BETWEEN %s AND %s", field, notStr, arg1, arg2)
 				whereClauses = append(whereClauses, betweenClause)
-				q.Args = append(q.Args, cond.Values[0], cond.Values[1])
+			case ConditionArray: // handle array operators
+				arrayClause, err := q.buildArrayClause(field, cond)
+				if err != nil {
+					return nil, err
+				}
+				whereClauses = append(whereClauses, arrayClause)
+			case ConditionJSON: // handle jsonb operators
+				jsonClause, err := q.buildJSONClause(field, cond)
+				if err != nil {
+					return nil, err
+				}
+				whereClauses = append(whereClauses, jsonClause)
 			default:
-				q.ArgCount++
-				clause := fmt.Sprintf("%s %s %s $%s", notStr, cond.Field, cond.Operator, strconv.Itoa(q.ArgCount))
+				op, err := normalizeOperator(cond.Operator)
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := q.bindValue(cond.Values[0])
+				if err != nil {
+					return nil, err
+				}
+
+				clause := fmt.Sprintf("%s %s %s %s", notStr, field, op, value)
 				whereClauses = append(whereClauses, clause)
-				q.Args = append(q.Args, cond.Values[0])
 			}
 		}
 
 		// Add logical operator between conditions, except for the last one
 		if i < len(conds)-1 && cond.NextLogicalOp != "" {
-			whereClauses = append(whereClauses, cond.NextLogicalOp)
+			logicalOp, err := normalizeLogicalOp(cond.NextLogicalOp)
+			if err != nil {
+				return nil, err
+			}
+			whereClauses = append(whereClauses, logicalOp)
 		}
 	}
-	return whereClauses, q.ArgCount
+	return whereClauses, nil
+}
+
+// top level conditions are joined with AND unless told otherwise
+func andConditions(conds []Condition) []Condition {
+	conds = slices.Clone(conds)
+	for i := range conds {
+		if conds[i].NextLogicalOp == "" {
+			conds[i].NextLogicalOp = "AND"
+		}
+	}
+	return conds
 }
 
 func (q *query) addWhere() error {
 	if len(q.whereConds) > 0 {
-		q.QueryString += " WHERE "
 		var whereClauses []string
-		whereClauses, q.ArgCount = q.buildWhereClauses(q.whereConds, whereClauses)
-		q.QueryString += strings.Join(whereClauses, " ")
+		whereClauses, err := q.buildWhereClauses(andConditions(q.whereConds), whereClauses)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return err
+		}
+
+		q.queryString += " WHERE "
+		q.queryString += strings.Join(whereClauses, " ")
 	}
 	return nil
 }
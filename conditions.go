@@ -0,0 +1,102 @@
+package database
+
+import "strings"
+
+// fluent helpers which compile down to Condition / WhereGroup trees
+// e.g. Where([]Condition{And(Eq("location", "FR"), Or(Gt("age", 30), In("fire_team", "echo", "foxtrot")))})
+
+func compare(field, operator string, value any) Condition {
+	return Condition{Field: field, Operator: operator, Values: []any{value}}
+}
+
+// field = value
+func Eq(field string, value any) Condition {
+	return compare(field, "=", value)
+}
+
+// field <> value
+func Neq(field string, value any) Condition {
+	return compare(field, "<>", value)
+}
+
+// field > value
+func Gt(field string, value any) Condition {
+	return compare(field, ">", value)
+}
+
+// field >= value
+func Gte(field string, value any) Condition {
+	return compare(field, ">=", value)
+}
+
+// field < value
+func Lt(field string, value any) Condition {
+	return compare(field, "<", value)
+}
+
+// field <= value
+func Lte(field string, value any) Condition {
+	return compare(field, "<=", value)
+}
+
+// field LIKE pattern
+func Like(field string, pattern any) Condition {
+	return compare(field, "LIKE", pattern)
+}
+
+// field ILIKE pattern, case insensitive
+func ILike(field string, pattern any) Condition {
+	return compare(field, "ILIKE", pattern)
+}
+
+// expr op value, e.g. Compare(JSONText("metadata", "plan"), "=", "pro")
+func Compare(expr Expr, operator string, value any) Condition {
+	return Condition{Expr: expr, Operator: operator, Values: []any{value}}
+}
+
+// field IN (values...), each value is its own placeholder, see EqAny for large lists
+func In(field string, values ...any) Condition {
+	return Condition{Field: field, Type: ConditionIn, Values: []any{values}}
+}
+
+// field BETWEEN low AND high
+func Between(field string, low, high any) Condition {
+	return Condition{Field: field, Type: ConditionBetween, Values: []any{low, high}}
+}
+
+// (fields...) op (values...), a row comparison
+func Tuple(fields []string, operator string, values ...any) Condition {
+	return Condition{Field: strings.Join(fields, ","), Operator: operator, Values: values, Type: ConditionTuple}
+}
+
+// group conditions joined by the logical operator
+func group(op string, conds []Condition) Condition {
+	grouped := make([]Condition, len(conds))
+	copy(grouped, conds)
+
+	for i := range grouped {
+		if i < len(grouped)-1 {
+			grouped[i].NextLogicalOp = op
+		} else {
+			grouped[i].NextLogicalOp = ""
+		}
+	}
+
+	return Condition{Nested: &WhereGroup{Conditions: grouped}}
+}
+
+// ( cond AND cond ... )
+func And(conds ...Condition) Condition {
+	return group("AND", conds)
+}
+
+// ( cond OR cond ... )
+func Or(conds ...Condition) Condition {
+	return group("OR", conds)
+}
+
+// negate a condition or group
+func Not(cond Condition) Condition {
+	cond.Not = !cond.Not
+	return cond
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestConditionBuilders(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	// SELECT * FROM people WHERE ( location = 'FR' AND ( shift_type = 'nocturnal' OR fire_team IN ('echo', 'foxtrot') ) AND NOT ( email ILIKE '%@naver.com' ) );
+	queryString1 := `SELECT * FROM people WHERE (  location = $1 AND (  shift_type = $2 OR fire_team  IN ($3, $4) ) AND NOT (  email ILIKE $5 ) );`
+	queryArgs1 := []driver.Value{`FR`, `nocturnal`, `echo`, `foxtrot`, `%@naver.com`}
+	query1, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(queryString1).WithArgs(queryArgs1...).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+	rows, err := query1.Select().For("people").Where([]Condition{
+		And(
+			Eq("location", "FR"),
+			Or(Eq("shift_type", "nocturnal"), In("fire_team", "echo", "foxtrot")),
+			Not(And(ILike("email", "%@naver.com"))),
+		),
+	}).Find()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+
+	// conditions in having and join clauses
+	// SELECT location FROM people JOIN teams ON ( people.team_id = teams.id AND NOT teams.archived = true ) GROUP BY location HAVING location <> 'FR';
+	queryString2 := `SELECT location FROM people JOIN teams ON (  people.team_id = teams.id AND NOT teams.archived = $1 ) WHERE _id  BETWEEN $2 AND $3 GROUP BY location HAVING  location <> $4;`
+	queryArgs2 := []driver.Value{true, `1`, `500`, `FR`}
+	query2, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(queryString2).WithArgs(queryArgs2...).WillReturnRows(sqlmock.NewRows([]string{"location"}).AddRow([]driver.Value{"EU"}...))
+
+	rows, err = query2.Select("location").For("people").Join([]JoinClause{
+		{JoinType: BasicJoin, Table: "teams", Condition: And(Eq("people.team_id", Col("teams.id")), Not(Eq("teams.archived", true)))},
+	}).Where([]Condition{
+		Between("_id", "1", "500"),
+	}).GroupBy([]string{"location"}).Having([]Condition{
+		Neq("location", "FR"),
+	}).Find()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+}
+
+// plain helpers listed in where are joined with AND
+func TestWhereDefaultsToAnd(t *testing.T) {
+	sqlStr, args, err := NewBuilder().Select("_id").For("people").Where([]Condition{
+		Eq("location", "FR"),
+		Gt("age", 3),
+		Or(Eq("shift_type", "nocturnal"), Like("email", "%@naver.com")),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people WHERE  location = $1 AND  age > $2 AND (  shift_type = $3 OR  email LIKE $4 );`, sqlStr)
+	assert.Equal(t, []any{"FR", 3, "nocturnal", "%@naver.com"}, args)
+
+	// explicit logical operators are kept
+	sqlStr, _, err = NewBuilder().Select("_id").For("people").Where([]Condition{
+		{Field: "location", Operator: "=", Values: []any{"FR"}, NextLogicalOp: "OR"},
+		Gt("age", 3),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people WHERE  location = $1 OR  age > $2;`, sqlStr)
+}
+
+// having should support the same conditions as where
+func TestHavingConditions(t *testing.T) {
+	sqlStr, args, err := NewBuilder().Select("team_id", "COUNT(*)").For("people").GroupBy([]string{"team_id"}).Having([]Condition{
+		Or(Gt("COUNT(*)", 10), Lt("SUM(score)", 100)),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT team_id, COUNT(*) FROM people GROUP BY team_id HAVING (  COUNT(*) > $1 OR  SUM(score) < $2 );`, sqlStr)
+	assert.Equal(t, []any{10, 100}, args)
+
+	sqlStr, args, err = NewBuilder().Select("team_id").For("people").GroupBy([]string{"team_id"}).Having([]Condition{
+		Between("COUNT(*)", 2, 5),
+		Not(Eq("MAX(score)", 0)),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT team_id FROM people GROUP BY team_id HAVING COUNT(*)  BETWEEN $1 AND $2 AND NOT MAX(score) = $3;`, sqlStr)
+	assert.Equal(t, []any{2, 5, 0}, args)
+
+	// explicit logical operators are kept
+	sqlStr, _, err = NewBuilder().Select("team_id").For("people").GroupBy([]string{"team_id"}).Having([]Condition{
+		{Field: "COUNT(*)", Operator: ">", Values: []any{1}, NextLogicalOp: "OR"},
+		In("MIN(level)", 1, 2),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT team_id FROM people GROUP BY team_id HAVING  COUNT(*) > $1 OR MIN(level)  IN ($2, $3);`, sqlStr)
+
+	_, _, err = NewBuilder().Select("team_id").For("people").GroupBy([]string{"team_id"}).Having([]Condition{
+		{Field: "COUNT(*)", Operator: "; DROP", Values: []any{1}},
+	}).ToSQL()
+	assert.NotNil(t, err)
+}
@@ -17,12 +17,13 @@ type ConnectionExecutor interface {
 // concrete implementation of ConnectionExecutor
 type postgresConnection struct {
 	db     *sql.DB // db instance handle
-	Host   string  `json:"host"`
-	DbName string  `json:"dbname"`
-	Port   string  `json:"port"`
-	User   string  `json:"user"`
-	Pass   string  `json:"pass"`
-	Ssl    string  `json:"ssl"`
+	stmts  *StmtCache
+	Host   string `json:"host"`
+	DbName string `json:"dbname"`
+	Port   string `json:"port"`
+	User   string `json:"user"`
+	Pass   string `json:"pass"`
+	Ssl    string `json:"ssl"`
 }
 
 // connect to the postgres db
@@ -56,14 +57,23 @@ func (c *postgresConnection) Connect() error {
 	}
 
 	c.db = db
+	c.stmts = NewStmtCache(db, DefaultStmtCacheSize)
 	return nil
 }
 
 // close the connection to the postgres db
 func (c *postgresConnection) Close() error {
+	if c.stmts != nil {
+		c.stmts.Close()
+	}
 	return c.db.Close()
 }
 
+// prepared statements cached for the connection
+func (c *postgresConnection) StmtCache() *StmtCache {
+	return c.stmts
+}
+
 // get a handle to the db instance
 func (c *postgresConnection) GetDB() *sql.DB {
 	return c.db
@@ -1,74 +1,95 @@
 package database
 
 import (
-	"database/sql"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
-func (q *query) makePlaceholders(n int) ([]string, error) {
+func (q *query) makePlaceholders(n int) ([]string, []string, error) {
 	placeholders := make([]string, n)
+	cols := make([]string, n)
 	for i := range placeholders {
-		q.ArgCount++
+		q.argCount++
 
 		if q.cols[i] == "" {
 			nerr := fmt.Errorf("column missing")
 			q.errors = append(q.errors, nerr)
 
-			return placeholders, nerr
+			return placeholders, cols, nerr
 		}
 
-		placeholders[i] = "$" + strconv.Itoa(q.ArgCount)
+		col, err := q.fieldIdent(q.cols[i])
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return placeholders, cols, err
+		}
+
+		cols[i] = col
+		placeholders[i] = "$" + strconv.Itoa(q.argCount)
 	}
 
-	q.Args = q.colValues
-	return placeholders, nil
+	q.args = append(q.args, q.colValues...)
+	return placeholders, cols, nil
 }
 
-func (q *query) BuildInsertQuery() (*sql.Stmt, error) {
+func (q *query) BuildInsertQuery() error {
+
+	err := q.buildInsert()
+	if err != nil {
+		return err
+	}
+
+	q.queryString += ";"
+
+	fmt.Println("insert query => ", q.queryString, q.args, q.argCount)
 
+	return nil
+}
+
+// builds the insert statement without the terminating semicolon
+func (q *query) buildInsert() error {
 	err := q.checkPreBuildErrors()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if len(q.cols) != len(q.colValues) {
-		return nil, fmt.Errorf("columns / values length mismatch")
+		return fmt.Errorf("columns / values length mismatch")
 	}
 
-	placeholders, err := q.makePlaceholders(len(q.cols))
+	err = q.addWith()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	q.QueryString = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.table, strings.Join(q.cols, ", "), strings.Join(placeholders, ", "))
-
-	q.QueryString += ";"
-
-	fmt.Println("insert query => ", q.QueryString, q.Args, q.ArgCount)
+	table, err := q.tableIdent(q.table)
+	if err != nil {
+		return err
+	}
 
-	stmt, err := q.conn.GetDB().Prepare(q.QueryString)
+	placeholders, cols, err := q.makePlaceholders(len(q.cols))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return stmt, nil
+	q.queryString += fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(cols, ", "), strings.Join(placeholders, ", "))
+
+	return q.addReturning()
 }
 
 func (q *query) Create() (int64, error) {
+	q = q.fresh()
 
-	stmt, err := q.BuildInsertQuery()
+	err := q.checkReturning()
 	if err != nil {
 		return 0, err
 	}
-	defer stmt.Close()
 
-	res, err := stmt.Exec(q.Args...)
+	err = q.BuildInsertQuery()
 	if err != nil {
 		return 0, err
 	}
 
-	c, err := res.RowsAffected()
-	return c, err
+	return q.exec()
 }
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// a common table expression prefixed to the statement
+type commonTable struct {
+	name      string
+	query     QueryExecutor
+	recursive QueryExecutor // recursive term, unioned with query
+}
+
+// add a common table expression, sub may be any statement kind, data
+// modifying statements expose their rows through Returning
+//
+//	q.With("recent", NewBuilder().For("orders").Where(...)).Select().For("recent")
+func (q *query) With(name string, sub QueryExecutor) QueryExecutor {
+	q = q.clone()
+
+	if sub == nil {
+		q.errors = append(q.errors, fmt.Errorf("common table %s missing query", name))
+	}
+
+	q.ctes = append(q.ctes, commonTable{name: name, query: sub})
+	return q
+}
+
+// add a recursive common table expression, the recursive term references the
+// table by name and is joined to the anchor with UNION ALL
+func (q *query) WithRecursive(name string, anchor, recursive QueryExecutor) QueryExecutor {
+	q = q.clone()
+
+	if anchor == nil || recursive == nil {
+		q.errors = append(q.errors, fmt.Errorf("recursive common table %s requires an anchor and a recursive query", name))
+	}
+
+	q.ctes = append(q.ctes, commonTable{name: name, query: anchor, recursive: recursive})
+	return q
+}
+
+// rows returned by an insert, update or delete statement
+func (q *query) Returning(cols ...string) QueryExecutor {
+	q = q.clone()
+	q.returning = append(q.returning, cols...)
+	return q
+}
+
+// check if the name refers to a common table of the query or an outer query
+func (q *query) isCommonTable(name string) bool {
+	for _, cte := range q.ctes {
+		if cte.name == name {
+			return true
+		}
+	}
+
+	table, ok := q.outerScope[name]
+	return ok && table == ""
+}
+
+// start the statement with its common tables, resets the query string
+func (q *query) addWith() error {
+	q.queryString = ""
+	if len(q.ctes) == 0 {
+		return nil
+	}
+
+	keyword := "WITH"
+	tables := make([]string, len(q.ctes))
+	for i, cte := range q.ctes {
+		name, err := q.aliasIdent(cte.name)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return err
+		}
+
+		sub, err := q.buildSubQuery(cte.query)
+		if err != nil {
+			return err
+		}
+
+		if cte.recursive != nil {
+			keyword = "WITH RECURSIVE"
+
+			rec, err := q.buildSubQuery(cte.recursive)
+			if err != nil {
+				return err
+			}
+			sub += " UNION ALL " + rec
+		}
+
+		tables[i] = fmt.Sprintf("%s AS (%s)", name, sub)
+	}
+
+	q.queryString = fmt.Sprintf("%s %s ", keyword, strings.Join(tables, ", "))
+	return nil
+}
+
+// executing drops the rows of a returning clause, they are read by ExecReturning
+func (q *query) checkReturning() error {
+	if len(q.returning) > 0 {
+		return fmt.Errorf("returning rows would be discarded, use ExecReturning")
+	}
+	return nil
+}
+
+// run the insert, update or delete statement set by As and collect the rows
+// of its returning clause
+//
+//	rows, err := q.Set(values).For("teams").Returning("_id").As(InsertStatement).ExecReturning()
+func (q *query) ExecReturning() ([][]any, error) {
+	c := q.fresh()
+
+	if c.kind == SelectStatement {
+		return nil, fmt.Errorf("returning requires an insert, update or delete statement")
+	}
+
+	if len(c.returning) == 0 {
+		return nil, fmt.Errorf("returning columns required")
+	}
+
+	err := c.build(c.kind)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := c.runner()
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := bindParams(c.args, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return queryRows(c.context(), db, c.queryString+";", args)
+}
+
+// render the returning clause of a data modifying statement
+func (q *query) addReturning() error {
+	if len(q.returning) == 0 {
+		return nil
+	}
+
+	cols := make([]string, len(q.returning))
+	for i, col := range q.returning {
+		var err error
+		cols[i], err = q.selectIdent(col)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return err
+		}
+	}
+
+	q.queryString += " RETURNING " + strings.Join(cols, ", ")
+	return nil
+}
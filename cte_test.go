@@ -0,0 +1,117 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCommonTables(t *testing.T) {
+	// recursive org chart, placeholders continue across every part
+	anchor := NewBuilder().Select("_id", "manager_id").For("people").Where([]Condition{Eq("_id", 1)})
+	reports := NewBuilder().Select("people._id", "people.manager_id").For("people").Join([]JoinClause{
+		{JoinType: InnerJoin, Table: "chart", Condition: Condition{Field: "people.manager_id", Operator: "=", Values: []any{Col("chart._id")}}},
+	}).Where([]Condition{Eq("people.active", true)})
+
+	sqlStr, args, err := NewBuilder().WithRecursive("chart", anchor, reports).Select("_id").For("chart").Where([]Condition{Neq("_id", 1)}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `WITH RECURSIVE chart AS (SELECT _id, manager_id FROM people WHERE  _id = $1 UNION ALL SELECT people._id, people.manager_id FROM people INNER JOIN chart ON people.manager_id = chart._id WHERE  people.active = $2) SELECT _id FROM chart WHERE  _id <> $3;`, sqlStr)
+	assert.Equal(t, []any{1, true, 1}, args)
+
+	// data modifying common table
+	expired := NewBuilder().For("sessions").Where([]Condition{Lt("expires_at", "2024-01-01")}).Returning("user_id").As(DeleteStatement)
+
+	sqlStr, args, err = NewBuilder().With("expired", expired).Set(map[string]any{"online": false}).For("users").Where([]Condition{
+		Eq("online", true),
+	}).As(UpdateStatement).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `WITH expired AS (DELETE FROM sessions WHERE  expires_at < $1 RETURNING user_id) UPDATE users SET online = $2 WHERE  online = $3;`, sqlStr)
+	assert.Equal(t, []any{"2024-01-01", false, true}, args)
+
+	// several common tables
+	paid := NewBuilder().Select("_id", "total").For("orders").Where([]Condition{Eq("status", "paid")})
+	big := NewBuilder().Select("_id").For("paid").Where([]Condition{Gt("total", 100)})
+
+	sqlStr, args, err = NewBuilder().With("paid", paid).With("big", big).Select("COUNT(*)").For("big").ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `WITH paid AS (SELECT _id, total FROM orders WHERE  status = $1), big AS (SELECT _id FROM paid WHERE  total > $2) SELECT COUNT(*) FROM big;`, sqlStr)
+	assert.Equal(t, []any{"paid", 100}, args)
+
+	// strict mode accepts the common table and its qualified columns
+	schema := Schema{"people": {"_id", "manager_id"}}
+	managers := NewBuilder().Select("manager_id").For("people")
+
+	sqlStr, _, err = NewBuilder(WithSchema(schema)).With("managers", managers).Select("managers.manager_id").For("managers").ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `WITH managers AS (SELECT manager_id FROM people) SELECT managers.manager_id FROM managers;`, sqlStr)
+
+	// unqualified columns only resolve against registered tables
+	_, _, err = NewBuilder(WithSchema(schema)).With("managers", managers).Select("manager_id").For("managers").ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unknown column "manager_id"`)
+
+	_, _, err = NewBuilder(WithSchema(schema)).With("managers", managers).Select("password").For("people").ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unknown column "password"`)
+
+	// insert returning
+	sqlStr, _, err = NewBuilder().Set(map[string]any{"name": "ops"}).For("teams").Returning("_id").As(InsertStatement).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `INSERT INTO teams (name) VALUES ($1) RETURNING _id;`, sqlStr)
+
+	// failure cases
+	_, _, err = NewBuilder().With("bad name", anchor).Select().For("people").ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().WithRecursive("chart", anchor, nil).Select().For("chart").ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().Select().For("people").Join([]JoinClause{
+		{JoinType: LeftJoin, Lateral: expired, Alias: "e"},
+	}).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "lateral join requires a select sub query")
+}
+
+func TestExecReturning(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(`DELETE FROM sessions WHERE  expires_at < $1 RETURNING user_id;`).WithArgs(`2024-01-01`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(int64(4)).AddRow(int64(7)))
+
+	expired := q.For("sessions").Where([]Condition{Lt("expires_at", "2024-01-01")}).Returning("user_id")
+
+	rows, err := expired.As(DeleteStatement).ExecReturning()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{int64(4)}, {int64(7)}}, rows)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// executing would drop the returned rows
+	_, err = expired.Delete()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "use ExecReturning")
+
+	_, err = expired.ExecReturning()
+	assert.NotNil(t, err)
+
+	_, err = q.For("sessions").Where([]Condition{Lt("expires_at", "2024-01-01")}).As(DeleteStatement).ExecReturning()
+	assert.NotNil(t, err)
+}
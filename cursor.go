@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+	"sync/atomic"
+)
+
+// default number of rows fetched per round trip
+const DefaultCursorBatchSize = 1000
+
+// options of a server side cursor
+type CursorOptions struct {
+	Name      string // generated when empty
+	BatchSize int    // rows per FETCH, defaults to DefaultCursorBatchSize
+	WithHold  bool   // declare outside of a transaction on a dedicated connection, see Cursor
+}
+
+// sequence for generated cursor names
+var cursorSeq atomic.Uint64
+
+// stream the select query through a server side cursor, at most BatchSize rows
+// are held in memory. The query must run in a transaction, see InTx, unless
+// the cursor is held: a held cursor is declared outside of any transaction on
+// a connection of its own and stays readable until it is closed.
+//
+// Each batch is read in full before its rows are yielded, so the loop body may
+// run other statements in the same transaction.
+//
+//	DECLARE name NO SCROLL CURSOR [WITH HOLD] FOR SELECT ...
+//	FETCH FORWARD n FROM name
+//	CLOSE name
+func (q *query) Cursor(opts CursorOptions) iter.Seq2[Row, error] {
+	fail := func(err error) iter.Seq2[Row, error] {
+		return func(yield func(Row, error) bool) {
+			yield(nil, err)
+		}
+	}
+
+	if opts.WithHold && q.tx != nil {
+		return fail(fmt.Errorf("held cursor runs outside of a transaction"))
+	}
+
+	if !opts.WithHold && q.tx == nil {
+		return fail(fmt.Errorf("cursor requires a transaction"))
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultCursorBatchSize
+	}
+	if batchSize < 0 {
+		return fail(fmt.Errorf("invalid cursor batch size %d", batchSize))
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = "orm_cursor_" + strconv.FormatUint(cursorSeq.Add(1), 10)
+	}
+
+	c := q.fresh()
+	cursorName, err := c.aliasIdent(name)
+	if err != nil {
+		return fail(err)
+	}
+
+	err = c.buildSelect()
+	if err != nil {
+		return fail(err)
+	}
+
+	args, err := bindParams(c.args, nil)
+	if err != nil {
+		return fail(err)
+	}
+
+	hold := ""
+	if opts.WithHold {
+		hold = " WITH HOLD"
+	}
+
+	declare := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR%s FOR %s;", cursorName, hold, c.queryString)
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s;", batchSize, cursorName)
+	closeCursor := fmt.Sprintf("CLOSE %s;", cursorName)
+
+	ctx := c.context()
+
+	return func(yield func(Row, error) bool) {
+		var db runner = c.tx
+		if opts.WithHold {
+			sqlDB, err := c.db()
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			// the cursor lives in the session, every statement must use the same connection
+			conn, err := sqlDB.Conn(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			defer conn.Close()
+			db = conn
+		}
+
+		_, err := db.ExecContext(ctx, declare, args...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		stopped := false
+		fetchErr := func() error {
+			for {
+				rows, err := queryRows(ctx, db, fetch, nil)
+				if err != nil {
+					return err
+				}
+
+				for _, row := range rows {
+					if !yield(row, nil) {
+						stopped = true
+						return nil
+					}
+				}
+
+				// a short batch means the cursor is exhausted
+				if len(rows) < batchSize {
+					return nil
+				}
+			}
+		}()
+
+		// closed even when the iteration is cancelled
+		_, closeErr := db.ExecContext(context.Background(), closeCursor)
+
+		// nothing can be reported once the loop stopped, and a failed fetch
+		// already aborted the transaction along with the cursor
+		switch {
+		case stopped:
+		case fetchErr != nil:
+			yield(nil, fetchErr)
+		case closeErr != nil:
+			yield(nil, closeErr)
+		}
+	}
+}
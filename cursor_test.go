@@ -0,0 +1,165 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCursor(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	// each batch is read before its rows are yielded, the loop body may use the transaction
+	mock.ExpectBegin()
+	mock.ExpectExec(`DECLARE export NO SCROLL CURSOR FOR SELECT _id FROM audit WHERE  actor = $1;`).WithArgs(`jpomfrette`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FETCH FORWARD 2 FROM export;`).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow(`1`).AddRow(`2`)).RowsWillBeClosed()
+	mock.ExpectExec(`UPDATE audit SET exported = $1 WHERE  _id = $2;`).WithArgs(true, `1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE audit SET exported = $1 WHERE  _id = $2;`).WithArgs(true, `2`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FETCH FORWARD 2 FROM export;`).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow(`3`)).RowsWillBeClosed()
+	mock.ExpectExec(`UPDATE audit SET exported = $1 WHERE  _id = $2;`).WithArgs(true, `3`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`CLOSE export;`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	tx, err := conn.GetDB().Begin()
+	assert.Nil(t, err)
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	var ids []any
+	for row, err := range q.Select("_id").For("audit").Where([]Condition{Eq("actor", "jpomfrette")}).InTx(tx).Cursor(CursorOptions{Name: "export", BatchSize: 2}) {
+		assert.Nil(t, err)
+		ids = append(ids, row[0])
+
+		_, err = q.Set(map[string]any{"exported": true}).For("audit").Where([]Condition{Eq("_id", row[0])}).InTx(tx).Update()
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []any{"1", "2", "3"}, ids)
+
+	assert.Nil(t, tx.Commit())
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestHeldCursor(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	// a held cursor runs on its own connection, outside of any transaction
+	mock.ExpectExec(`DECLARE export NO SCROLL CURSOR WITH HOLD FOR SELECT _id FROM audit;`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FETCH FORWARD 2 FROM export;`).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow(`1`)).RowsWillBeClosed()
+	mock.ExpectExec(`CLOSE export;`).WillReturnError(fmt.Errorf("cursor \"export\" does not exist"))
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	var errs []error
+	for row, err := range q.Select("_id").For("audit").Cursor(CursorOptions{Name: "export", BatchSize: 2, WithHold: true}) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		assert.Equal(t, Row{"1"}, row)
+	}
+
+	// the close error is reported
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "does not exist")
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	mock.ExpectBegin()
+
+	tx, err := conn.GetDB().Begin()
+	assert.Nil(t, err)
+
+	for _, err := range q.Select("_id").For("audit").InTx(tx).Cursor(CursorOptions{WithHold: true}) {
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "held cursor runs outside of a transaction")
+	}
+}
+
+func TestCursorEarlyBreak(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DECLARE export NO SCROLL CURSOR FOR SELECT _id FROM audit;`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FETCH FORWARD 1000 FROM export;`).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow(`1`).AddRow(`2`)).RowsWillBeClosed()
+	mock.ExpectExec(`CLOSE export;`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	tx, err := conn.GetDB().Begin()
+	assert.Nil(t, err)
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	for row, err := range q.Select("_id").For("audit").InTx(tx).Cursor(CursorOptions{Name: "export"}) {
+		assert.Nil(t, err)
+		assert.Equal(t, Row{"1"}, row)
+		break
+	}
+
+	assert.Nil(t, tx.Rollback())
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// a cursor outside of a transaction is rejected
+	for _, err := range q.Select("_id").For("audit").Cursor(CursorOptions{}) {
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "cursor requires a transaction")
+	}
+}
+
+func TestQueryInTx(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM audit WHERE  actor = $1;`).WithArgs(`jpomfrette`).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(`SELECT _id FROM audit;`).WillReturnRows(sqlmock.NewRows([]string{"_id"}))
+	mock.ExpectCommit()
+
+	tx, err := conn.GetDB().Begin()
+	assert.Nil(t, err)
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	count, err := q.For("audit").Where([]Condition{Eq("actor", "jpomfrette")}).InTx(tx).Delete()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+
+	rows, err := q.Select("_id").For("audit").InTx(tx).Find()
+	assert.Nil(t, err)
+	assert.Empty(t, rows)
+
+	assert.Nil(t, tx.Commit())
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -1,40 +1,58 @@
 package database
 
 import (
-	"database/sql"
 	"fmt"
 )
 
-func (q *query) BuildDeleteQuery() (*sql.Stmt, error) {
+func (q *query) BuildDeleteQuery() error {
 
+	err := q.buildDelete()
+	if err != nil {
+		return err
+	}
+
+	q.queryString += ";"
+
+	fmt.Println("delete query => ", q.queryString, q.args)
+
+	return nil
+}
+
+// builds the delete statement without the terminating semicolon
+func (q *query) buildDelete() error {
 	err := q.checkPreBuildErrors()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if len(q.whereConds) == 0 {
-		return nil, fmt.Errorf("where clause required for delete")
+		return fmt.Errorf("where clause required for delete")
 	}
 
-	// Start building the query
-	q.QueryString = fmt.Sprintf("DELETE FROM %s", q.table)
-
-	// Add WHERE conditions
-	q.addWhere()
+	err = q.addWith()
+	if err != nil {
+		return err
+	}
 
-	q.QueryString += ";"
+	table, err := q.tableIdent(q.table)
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("delete query => ", q.QueryString, q.Args)
+	// Start building the query
+	q.queryString += fmt.Sprintf("DELETE FROM %s", table)
 
-	stmt, err := q.conn.GetDB().Prepare(q.QueryString)
+	// Add WHERE conditions
+	err = q.addWhere()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return stmt, nil
+	return q.addReturning()
 }
 
 func (q *query) DeleteOne(id string) (int64, error) {
+	q = q.clone()
 	q.whereConds = []Condition{
 		{Field: "_id", Operator: "=", Values: []any{id}},
 	}
@@ -52,18 +70,17 @@ func (q *query) DeleteOne(id string) (int64, error) {
 }
 
 func (q *query) Delete() (int64, error) {
+	q = q.fresh()
 
-	stmt, err := q.BuildDeleteQuery()
+	err := q.checkReturning()
 	if err != nil {
 		return 0, err
 	}
-	defer stmt.Close()
 
-	res, err := stmt.Exec(q.Args...)
+	err = q.BuildDeleteQuery()
 	if err != nil {
 		return 0, err
 	}
 
-	c, err := res.RowsAffected()
-	return c, err
+	return q.exec()
 }
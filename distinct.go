@@ -0,0 +1,119 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// drop duplicate rows
+func (q *query) Distinct() QueryExecutor {
+	q = q.clone()
+	q.distinct = true
+	q.distinctOn = nil
+	return q
+}
+
+// keep the first row of each set of rows with equal values of the columns,
+// e.g. the latest row per group. The columns must lead the order by clauses,
+// which decide the row kept.
+func (q *query) DistinctOn(cols ...string) QueryExecutor {
+	q = q.clone()
+
+	if len(cols) == 0 {
+		q.errors = append(q.errors, fmt.Errorf("empty distinct on columns not allowed"))
+	}
+
+	q.distinct = false
+	q.distinctOn = cols
+	return q
+}
+
+func (q *query) isDistinct() bool {
+	return q.distinct || len(q.distinctOn) > 0
+}
+
+// the column sorted by an order by clause, positions resolve to the select
+// list. Empty with a description of the key when it is not a column.
+func (q *query) orderColumn(order OrderClause) (string, string) {
+	switch {
+	case order.Field != "":
+		return strings.TrimSpace(order.Field), ""
+	case order.Expr != nil:
+		return "", "an expression"
+	}
+
+	if order.Position < 1 || order.Position > len(q.selects) {
+		return "", fmt.Sprintf("position %d", order.Position)
+	}
+
+	col, ok := q.selects[order.Position-1].(selectColumn)
+	if !ok || col == "*" || strings.HasSuffix(string(col), ".*") {
+		return "", fmt.Sprintf("position %d", order.Position)
+	}
+
+	name := strings.TrimSpace(string(col))
+	if match := aliasRe.FindStringSubmatch(name); match != nil {
+		name = match[1]
+	}
+	return name, ""
+}
+
+// a column of the from table without its table qualifier
+func (q *query) unqualified(col string) string {
+	col = strings.TrimSpace(col)
+	if q.table != "" && strings.HasPrefix(col, q.table+".") {
+		return col[len(q.table)+1:]
+	}
+	return col
+}
+
+// render the distinct modifier of the select, with its trailing space
+func (q *query) buildDistinct() (string, error) {
+	if q.distinct {
+		return "DISTINCT ", nil
+	}
+
+	if len(q.distinctOn) == 0 {
+		return "", nil
+	}
+
+	// postgres requires the distinct on columns to match the leftmost order by fields
+	if len(q.orderBy) > 0 {
+		if len(q.orderBy) < len(q.distinctOn) {
+			return "", fmt.Errorf("distinct on columns must lead the order by clauses")
+		}
+
+		distinctCols := make([]string, len(q.distinctOn))
+		for i, col := range q.distinctOn {
+			ident, err := q.fieldIdent(q.unqualified(col))
+			if err != nil {
+				return "", err
+			}
+			distinctCols[i] = ident
+		}
+
+		for _, order := range q.orderBy[:len(q.distinctOn)] {
+			col, desc := q.orderColumn(order)
+			if col == "" {
+				return "", fmt.Errorf("distinct on columns must lead the order by clauses, found %s", desc)
+			}
+
+			ident, err := q.fieldIdent(q.unqualified(col))
+			if err != nil {
+				return "", err
+			}
+
+			if !slices.Contains(distinctCols, ident) {
+				return "", fmt.Errorf("distinct on columns must lead the order by clauses, found %s", col)
+			}
+		}
+	}
+
+	cols, err := q.fieldList(q.distinctOn)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("DISTINCT ON (%s) ", cols), nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistinct(t *testing.T) {
+	sqlStr, _, err := NewBuilder().Select("location").For("people").Distinct().ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT DISTINCT location FROM people;`, sqlStr)
+
+	// latest reading per sensor
+	latest := NewBuilder().Select("sensor_id", "value", "read_at").For("readings").DistinctOn("sensor_id").OrderBy([]OrderClause{
+		{Field: "sensor_id", Order: "ASC"},
+		{Field: "read_at", Order: "DESC"},
+	})
+
+	sqlStr, _, err = latest.ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT DISTINCT ON (sensor_id) sensor_id, value, read_at FROM readings ORDER BY sensor_id ASC, read_at DESC;`, sqlStr)
+
+	// the leading order by fields may come in any order
+	sqlStr, _, err = NewBuilder().Select().For("readings").DistinctOn("site", "sensor_id").OrderBy([]OrderClause{
+		{Field: "sensor_id", Order: "ASC"},
+		{Field: "site", Order: "ASC"},
+		{Field: "read_at", Order: "DESC"},
+	}).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT DISTINCT ON (site, sensor_id) * FROM readings ORDER BY sensor_id ASC, site ASC, read_at DESC;`, sqlStr)
+
+	// positions resolve to the select list and the from table qualifier is optional
+	sqlStr, _, err = NewBuilder().Select("readings.sensor_id", "value").For("readings").DistinctOn("sensor_id").OrderBy([]OrderClause{
+		{Position: 1, Order: Asc},
+		{Field: "value", Order: Desc},
+	}).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT DISTINCT ON (sensor_id) readings.sensor_id, value FROM readings ORDER BY 1 ASC, value DESC;`, sqlStr)
+
+	sqlStr, _, err = NewBuilder().Select("sensor_id", "value").For("readings").DistinctOn("readings.sensor_id").OrderBy([]OrderClause{
+		{Field: "sensor_id", Order: Asc},
+	}).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT DISTINCT ON (readings.sensor_id) sensor_id, value FROM readings ORDER BY sensor_id ASC;`, sqlStr)
+
+	// distinct queries are counted through a sub query
+	c := latest.(*query).fresh()
+	err = c.buildCount()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT COUNT(*) FROM (SELECT DISTINCT ON (sensor_id) sensor_id, value, read_at FROM readings) AS counted`, c.queryString)
+
+	// failure cases
+	_, _, err = NewBuilder().Select().For("readings").DistinctOn("sensor_id").OrderBy([]OrderClause{
+		{Field: "read_at", Order: "DESC"},
+	}).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "distinct on columns must lead the order by clauses")
+
+	_, _, err = NewBuilder().Select().For("readings").DistinctOn("sensor_id").OrderBy([]OrderClause{
+		{Expr: Op("value", "-", 10), Order: Asc},
+	}).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "found an expression")
+
+	_, _, err = NewBuilder().Select().For("readings").DistinctOn("sensor_id").OrderBy([]OrderClause{
+		{Position: 1, Order: Asc},
+	}).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "found position 1")
+
+	_, _, err = NewBuilder().Select("value", "sensor_id").For("readings").DistinctOn("sensor_id").OrderBy([]OrderClause{
+		{Position: 1, Order: Asc},
+	}).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "found value")
+
+	_, _, err = NewBuilder().Select().For("readings").DistinctOn().ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().Select().For("readings").DistinctOn("sensor_id) x; --").ToSQL()
+	assert.NotNil(t, err)
+}
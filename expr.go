@@ -0,0 +1,93 @@
+package database
+
+import (
+	"strings"
+)
+
+// Expr is a typed sql expression usable as a select column
+type Expr interface {
+	// render the expression, values are bound as placeholders of the query
+	buildExpr(q *query) (string, error)
+}
+
+// a select column given by name, e.g. "people.email" or "COUNT(*) AS total"
+type selectColumn string
+
+func (c selectColumn) buildExpr(q *query) (string, error) {
+	return q.selectIdent(string(c))
+}
+
+// an expression with an alias
+type aliasedExpr struct {
+	expr  Expr
+	alias string
+}
+
+func (a aliasedExpr) buildExpr(q *query) (string, error) {
+	expr, err := a.expr.buildExpr(q)
+	if err != nil {
+		return "", err
+	}
+
+	alias, err := q.aliasIdent(a.alias)
+	if err != nil {
+		return "", err
+	}
+
+	return expr + " AS " + alias, nil
+}
+
+// name a select expression, the alias may be referenced by order by
+func Alias(expr Expr, alias string) Expr {
+	return aliasedExpr{expr: expr, alias: alias}
+}
+
+// alias of a select expression, empty when it has none
+func exprAlias(expr Expr) string {
+	switch e := expr.(type) {
+	case aliasedExpr:
+		return e.alias
+	case selectColumn:
+		if match := aliasRe.FindStringSubmatch(strings.TrimSpace(string(e))); match != nil {
+			return match[2]
+		}
+	}
+	return ""
+}
+
+// render a list of columns
+func (q *query) fieldList(fields []string) (string, error) {
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		var err error
+		cols[i], err = q.fieldIdent(f)
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(cols, ", "), nil
+}
+
+// bind a list of values
+func (q *query) valueList(values []any) (string, error) {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		var err error
+		placeholders[i], err = q.bindValue(v)
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(placeholders, ", "), nil
+}
+
+// join rendered parts, skipping empty ones
+func joinParts(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
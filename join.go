@@ -0,0 +1,219 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// join types accepted by the builder
+var validJoinTypes = map[JoinType]bool{
+	BasicJoin:      true,
+	InnerJoin:      true,
+	OuterJoin:      true,
+	LeftJoin:       true,
+	LeftOuterJoin:  true,
+	RightJoin:      true,
+	RightOuterJoin: true,
+	FullJoin:       true,
+	CrossJoin:      true,
+}
+
+// validate the conditions of a join clause
+func validJoinConditions(conds []Condition) bool {
+	for _, cond := range conds {
+		if cond.Nested != nil {
+			if !validJoinConditions(cond.Nested.Conditions) {
+				return false
+			}
+			continue
+		}
+
+		if cond.Type == ConditionRaw {
+			if cond.Expr == nil {
+				return false
+			}
+			continue
+		}
+
+		if (cond.Field == "" && cond.Expr == nil) || len(cond.Values) == 0 || cond.Values[0] == nil {
+			return false
+		}
+
+		if cond.Type == ConditionStandard && cond.Operator == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// collect the ON conditions of a join, empty if none were set
+func (join JoinClause) onConditions() []Condition {
+	if len(join.Conditions) > 0 {
+		return join.Conditions
+	}
+
+	if join.Condition.Field != "" || join.Condition.Expr != nil || join.Condition.Nested != nil {
+		return []Condition{join.Condition}
+	}
+
+	return nil
+}
+
+// render a sub query inline, continuing the placeholder numbering of q
+func (q *query) buildSubQuery(sub QueryExecutor) (string, error) {
+	sq, ok := sub.(*query)
+	if !ok || sq == nil {
+		return "", fmt.Errorf("unsupported sub query")
+	}
+
+	c := sq.fresh()
+	c.argCount = q.argCount
+
+	// correlated references to the outer query
+	c.outerScope = q.scope()
+
+	// strict mode and quoting of the outer query apply to the whole statement
+	if q.schema != nil {
+		c.schema = q.schema
+	}
+	if q.quoteIdents {
+		c.quoteIdents = true
+	}
+	if c.tsConfig == "" {
+		c.tsConfig = q.tsConfig
+	}
+
+	err := c.build(c.kind)
+	if err != nil {
+		return "", err
+	}
+
+	q.argCount = c.argCount
+	q.args = append(q.args, c.args...)
+
+	return c.queryString, nil
+}
+
+// build a single join clause
+func (q *query) buildJoin(join JoinClause) (string, error) {
+	if join.JoinType == "" || (join.Table == "" && join.Lateral == nil && join.Source == nil) {
+		return "", fmt.Errorf("join missing type/table")
+	}
+
+	if !validJoinTypes[join.JoinType] {
+		return "", fmt.Errorf("invalid join type %s", join.JoinType)
+	}
+
+	conds := join.onConditions()
+	isCross := join.JoinType == CrossJoin
+
+	if join.Natural && (isCross || len(conds) > 0 || len(join.Using) > 0) {
+		return "", fmt.Errorf("natural join cannot have conditions")
+	}
+
+	if isCross && (len(conds) > 0 || len(join.Using) > 0) {
+		return "", fmt.Errorf("cross join cannot have conditions")
+	}
+
+	if len(conds) > 0 && len(join.Using) > 0 {
+		return "", fmt.Errorf("join cannot have both on and using")
+	}
+
+	joinStr := string(join.JoinType)
+	if join.Natural {
+		joinStr = "NATURAL " + joinStr
+	}
+
+	// join source
+	var source string
+	if join.Lateral != nil {
+		if join.Alias == "" {
+			return "", fmt.Errorf("lateral join requires an alias")
+		}
+
+		if sq, ok := join.Lateral.(*query); ok && sq.kind != SelectStatement {
+			return "", fmt.Errorf("lateral join requires a select sub query")
+		}
+
+		sub, err := q.buildSubQuery(join.Lateral)
+		if err != nil {
+			return "", err
+		}
+		source = fmt.Sprintf("LATERAL (%s)", sub)
+	} else if join.Source != nil {
+		if join.Alias == "" {
+			return "", fmt.Errorf("join source requires an alias")
+		}
+
+		src, err := join.Source.buildExpr(q)
+		if err != nil {
+			return "", err
+		}
+		source = src
+	} else {
+		table, err := q.tableIdent(join.Table)
+		if err != nil {
+			return "", err
+		}
+		source = table
+	}
+
+	if join.Alias != "" {
+		alias, err := q.aliasIdent(join.Alias)
+		if err != nil {
+			return "", err
+		}
+		source += " AS " + alias
+	}
+
+	clause := fmt.Sprintf(" %s %s", joinStr, source)
+
+	switch {
+	case join.Natural || isCross:
+	case len(join.Using) > 0:
+		usingCols := make([]string, len(join.Using))
+		for i, col := range join.Using {
+			if col == "" {
+				return "", fmt.Errorf("join using column empty")
+			}
+
+			field, err := q.fieldIdent(col)
+			if err != nil {
+				return "", err
+			}
+			usingCols[i] = field
+		}
+		clause += fmt.Sprintf(" USING (%s)", strings.Join(usingCols, ", "))
+	case len(conds) > 0:
+		if !validJoinConditions(conds) {
+			return "", fmt.Errorf("join condition invalid")
+		}
+
+		var onClauses []string
+		onClauses, err := q.buildWhereClauses(andConditions(conds), onClauses)
+		if err != nil {
+			return "", err
+		}
+		clause += " ON " + strings.TrimSpace(strings.Join(onClauses, " "))
+	case join.Lateral != nil:
+		// a lateral sub query is usually correlated through its own where clause
+		clause += " ON true"
+	default:
+		return "", fmt.Errorf("join condition invalid")
+	}
+
+	return clause, nil
+}
+
+func (q *query) addJoins() error {
+	for _, join := range q.joins {
+		clause, err := q.buildJoin(join)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return err
+		}
+
+		q.queryString += clause
+	}
+	return nil
+}
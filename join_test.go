@@ -0,0 +1,105 @@
+package database
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestJoinVariants(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	// joins without a where clause
+	// SELECT * FROM members AS m INNER JOIN teams AS t ON m.team_id = t.id;
+	queryString1 := `SELECT * FROM members INNER JOIN teams AS t ON members.team_id = t.id;`
+	query1, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(queryString1).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+	rows, err := query1.Select().For("members").Join([]JoinClause{
+		{JoinType: InnerJoin, Table: "teams", Alias: "t", Condition: Condition{Field: "members.team_id", Operator: "=", Values: []any{Col("t.id")}}},
+	}).Find()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+
+	// SELECT * FROM members CROSS JOIN shifts NATURAL LEFT JOIN roles FULL OUTER JOIN teams USING (team_id, region);
+	queryString2 := `SELECT * FROM members CROSS JOIN shifts NATURAL LEFT JOIN roles FULL OUTER JOIN teams USING (team_id, region);`
+	query2, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(queryString2).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+	rows, err = query2.Select().For("members").Join([]JoinClause{
+		{JoinType: CrossJoin, Table: "shifts"},
+		{JoinType: LeftJoin, Table: "roles", Natural: true},
+		{JoinType: OuterJoin, Table: "teams", Using: []string{"team_id", "region"}},
+	}).Find()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+
+	// lateral sub query continues the placeholder numbering
+	// SELECT * FROM teams LEFT JOIN LATERAL (SELECT _id FROM members WHERE members.team_id = teams.id AND active = true LIMIT 3) AS m ON true WHERE region = 'EU';
+	queryString3 := `SELECT * FROM teams LEFT JOIN LATERAL (SELECT _id FROM members WHERE  members.team_id = teams.id AND  active = $1 LIMIT $2) AS m ON true WHERE  region = $3;`
+	queryArgs3 := []driver.Value{true, 3, `EU`}
+	query3, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	sub, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(queryString3).WithArgs(queryArgs3...).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+	rows, err = query3.Select().For("teams").Join([]JoinClause{
+		{JoinType: LeftJoin, Alias: "m", Lateral: sub.Select("_id").For("members").Where([]Condition{
+			{Field: "members.team_id", Operator: "=", Values: []any{Col("teams.id")}, NextLogicalOp: "AND"},
+			{Field: "active", Operator: "=", Values: []any{true}},
+		}).Limit(3)},
+	}).Where([]Condition{
+		{Field: "region", Operator: "=", Values: []any{"EU"}},
+	}).Find()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+}
+
+func TestInvalidJoins(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	defer conn.Close()
+
+	invalid := []struct {
+		join JoinClause
+		err  string
+	}{
+		{JoinClause{JoinType: "OUT JOIN", Table: "teams", Using: []string{"id"}}, "invalid join type OUT JOIN"},
+		{JoinClause{JoinType: CrossJoin, Table: "teams", Using: []string{"id"}}, "cross join cannot have conditions"},
+		{JoinClause{JoinType: BasicJoin, Table: "teams", Natural: true, Using: []string{"id"}}, "natural join cannot have conditions"},
+		{JoinClause{JoinType: BasicJoin, Table: "teams"}, "join condition invalid"},
+		{JoinClause{JoinType: BasicJoin, Lateral: &query{}}, "lateral join requires an alias"},
+	}
+
+	for _, tc := range invalid {
+		q, err := NewQuery(conn)
+		assert.Nil(t, err)
+
+		_, err = q.Select().For("members").Join([]JoinClause{tc.join}).Find()
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), tc.err)
+	}
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// path into a jsonb column, e.g. metadata->'address'->>'city'
+type JSONPath struct {
+	field   string
+	path    []any // string keys and int array indexes
+	text    bool  // the last step returns text
+	extract bool  // render the path as one #> / #>> text array
+}
+
+// path into a jsonb column returning jsonb, keys are strings and array
+// indexes ints
+//
+//	SelectExpr(JSONField("metadata", "tags", 0).As("first_tag"))
+func JSONField(field string, path ...any) JSONPath {
+	return JSONPath{field: field, path: path}
+}
+
+// path into a jsonb column returning text, usable in conditions
+//
+//	Where([]Condition{Compare(JSONText("metadata", "plan"), "=", "pro")})
+func JSONText(field string, path ...any) JSONPath {
+	return JSONPath{field: field, path: path, text: true}
+}
+
+// path into a jsonb column as a single text array, e.g. metadata #>> '{address,city}'
+func JSONExtract(field string, path ...string) JSONPath {
+	steps := make([]any, len(path))
+	for i, p := range path {
+		steps[i] = p
+	}
+	return JSONPath{field: field, path: steps, text: true, extract: true}
+}
+
+// the path returning jsonb instead of text
+func (p JSONPath) JSON() JSONPath {
+	p.text = false
+	return p
+}
+
+// name the result column
+func (p JSONPath) As(alias string) Expr {
+	return Alias(p, alias)
+}
+
+// render a string literal, standard_conforming_strings does not matter to
+// escape strings
+func quoteLiteral(s string) (string, error) {
+	if strings.ContainsRune(s, 0) {
+		return "", fmt.Errorf("invalid literal %q", s)
+	}
+
+	quoted := "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	if strings.Contains(s, `\`) {
+		quoted = "E" + strings.ReplaceAll(quoted, `\`, `\\`)
+	}
+	return quoted, nil
+}
+
+// path keys are rendered as literals, the same path must render the same
+// sql in select and group by so it cannot use placeholders
+func (p JSONPath) buildExpr(q *query) (string, error) {
+	if len(p.path) == 0 {
+		return "", fmt.Errorf("json path on %s requires a key", p.field)
+	}
+
+	field, err := q.fieldIdent(p.field)
+	if err != nil {
+		return "", err
+	}
+
+	if p.extract {
+		keys := make([]string, len(p.path))
+		for i, key := range p.path {
+			s := key.(string)
+			keys[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+		}
+
+		lit, err := quoteLiteral("{" + strings.Join(keys, ",") + "}")
+		if err != nil {
+			return "", err
+		}
+
+		op := "#>"
+		if p.text {
+			op = "#>>"
+		}
+		return fmt.Sprintf("%s %s %s", field, op, lit), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(field)
+	for i, key := range p.path {
+		op := "->"
+		if p.text && i == len(p.path)-1 {
+			op = "->>"
+		}
+		sb.WriteString(op)
+
+		switch k := key.(type) {
+		case string:
+			lit, err := quoteLiteral(k)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(lit)
+		case int:
+			sb.WriteString(strconv.Itoa(k))
+		default:
+			return "", fmt.Errorf("invalid json path key %T", key)
+		}
+	}
+	return sb.String(), nil
+}
+
+// jsonb operators of json conditions and the type their value is bound as
+var jsonOperators = map[string]string{
+	"@>": "jsonb",
+	"<@": "jsonb",
+	"?":  "text",
+	"?|": "text[]",
+	"?&": "text[]",
+	"@?": "jsonpath",
+	"@@": "jsonpath",
+}
+
+// field @> value, the value is encoded as json unless it is a json.RawMessage
+// or []byte document
+func JSONContains(field string, value any) Condition {
+	return Condition{Field: field, Type: ConditionJSON, Operator: "@>", Values: []any{value}}
+}
+
+// field <@ value, the value is encoded as json unless it is a json.RawMessage
+// or []byte document
+func JSONContainedBy(field string, value any) Condition {
+	return Condition{Field: field, Type: ConditionJSON, Operator: "<@", Values: []any{value}}
+}
+
+// field ? key, the top level key or array element exists
+func JSONHasKey(field, key string) Condition {
+	return Condition{Field: field, Type: ConditionJSON, Operator: "?", Values: []any{key}}
+}
+
+// field ?| keys, any of the keys exists
+func JSONHasAnyKey(field string, keys ...string) Condition {
+	return Condition{Field: field, Type: ConditionJSON, Operator: "?|", Values: []any{keys}}
+}
+
+// field ?& keys, all of the keys exist
+func JSONHasAllKeys(field string, keys ...string) Condition {
+	return Condition{Field: field, Type: ConditionJSON, Operator: "?&", Values: []any{keys}}
+}
+
+// field @? path, the jsonpath returns an item, e.g. '$.tags[*] ? (@ == "vip")'
+func JSONPathExists(field, path string) Condition {
+	return Condition{Field: field, Type: ConditionJSON, Operator: "@?", Values: []any{path}}
+}
+
+// field @@ path, the jsonpath predicate is true, e.g. '$.age > 30'
+func JSONPathMatch(field, path string) Condition {
+	return Condition{Field: field, Type: ConditionJSON, Operator: "@@", Values: []any{path}}
+}
+
+// encode a condition value for the json operator
+func jsonValue(op string, value any) (any, error) {
+	// named parameters are encoded once their value is known
+	if param, ok := value.(Param); ok {
+		return encodedParam{name: param, encode: func(v any) (any, error) {
+			return jsonValue(op, v)
+		}}, nil
+	}
+
+	switch jsonOperators[op] {
+	case "jsonb":
+		// raw documents are sent as is, everything else, strings included, is encoded
+		switch v := value.(type) {
+		case []byte:
+			return string(v), nil
+		case json.RawMessage:
+			return string(v), nil
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode json value => %s", err.Error())
+		}
+		return string(raw), nil
+	case "text[]":
+		keys, ok := value.([]string)
+		if !ok {
+			return nil, fmt.Errorf("%s requires a list of keys", op)
+		}
+		return pq.Array(keys), nil
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s requires a string value", op)
+		}
+		return s, nil
+	}
+}
+
+// build a jsonb operator condition, e.g. metadata @> $1::jsonb
+func (q *query) buildJSONClause(field string, cond Condition) (string, error) {
+	op := strings.TrimSpace(cond.Operator)
+	cast, ok := jsonOperators[op]
+	if !ok {
+		return "", fmt.Errorf("invalid json operator %q", cond.Operator)
+	}
+
+	value, err := jsonValue(op, cond.Values[0])
+	if err != nil {
+		return "", err
+	}
+
+	ph, err := q.bindValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	notStr := ""
+	if cond.Not {
+		notStr = "NOT "
+	}
+
+	return fmt.Sprintf("%s%s %s %s::%s", notStr, field, op, ph, cast), nil
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestJSONConditions(t *testing.T) {
+	sqlStr, args, err := NewBuilder().Select("_id").For("accounts").Where([]Condition{
+		And(
+			JSONContains("metadata", map[string]any{"plan": "pro"}),
+			JSONHasKey("metadata", "trial_end"),
+			JSONHasAnyKey("metadata", "vip", "partner"),
+			JSONHasAllKeys("metadata", "billing", "owner"),
+			JSONPathExists("metadata", `$.tags[*] ? (@ == "beta")`),
+			JSONPathMatch("metadata", `$.seats > 10`),
+			JSONContainedBy("settings", json.RawMessage(`{"theme": "dark", "beta": true}`)),
+			JSONContains("tags", "vip"),
+		),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM accounts WHERE ( metadata @> $1::jsonb AND metadata ? $2::text AND metadata ?| $3::text[] AND metadata ?& $4::text[] AND metadata @? $5::jsonpath AND metadata @@ $6::jsonpath AND settings <@ $7::jsonb AND tags @> $8::jsonb );`, sqlStr)
+	assert.Equal(t, []any{
+		`{"plan":"pro"}`, "trial_end", pq.Array([]string{"vip", "partner"}), pq.Array([]string{"billing", "owner"}),
+		`$.tags[*] ? (@ == "beta")`, `$.seats > 10`, `{"theme": "dark", "beta": true}`, `"vip"`,
+	}, args)
+
+	// path access on the left of a condition
+	sqlStr, args, err = NewBuilder().Select("_id").For("accounts").Where([]Condition{
+		And(
+			Compare(JSONText("metadata", "address", "city"), "=", "Lyon"),
+			Compare(JSONExtract("metadata", "owner", "email"), "ILIKE", "%@example.com"),
+		),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM accounts WHERE (  metadata->'address'->>'city' = $1 AND  metadata #>> '{"owner","email"}' ILIKE $2 );`, sqlStr)
+	assert.Equal(t, []any{"Lyon", "%@example.com"}, args)
+}
+
+func TestJSONPaths(t *testing.T) {
+	plan := JSONText("metadata", "plan")
+
+	sqlStr, _, err := NewBuilder().Select("COUNT(*)").SelectExpr(plan.As("plan"), JSONField("metadata", "tags", 0)).For("accounts").
+		GroupByExpr(plan, JSONField("metadata", "tags", 0)).
+		OrderBy([]OrderClause{{Expr: JSONText("metadata", "plan"), Order: Asc}}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT COUNT(*), metadata->>'plan' AS plan, metadata->'tags'->0 FROM accounts GROUP BY metadata->>'plan', metadata->'tags'->0 ORDER BY metadata->>'plan' ASC;`, sqlStr)
+
+	// keys are escaped literals
+	sqlStr, _, err = NewBuilder().SelectExpr(JSONText("metadata", "it's"), JSONText("metadata", `a\b`).JSON()).For("accounts").ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT metadata->>'it''s', metadata->E'a\\b' FROM accounts;`, sqlStr)
+
+	// failure cases
+	_, _, err = NewBuilder().SelectExpr(JSONText("metadata")).For("accounts").ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().SelectExpr(JSONText("metadata", 1.5)).For("accounts").ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().Select().For("accounts").Where([]Condition{
+		{Field: "metadata", Type: ConditionJSON, Operator: "->", Values: []any{"x"}},
+	}).ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().Select().For("accounts").Where([]Condition{
+		{Field: "metadata", Type: ConditionJSON, Operator: "?|", Values: []any{"x"}},
+	}).ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().Select().For("accounts").Where([]Condition{
+		JSONContains("metadata", func() {}),
+	}).ToSQL()
+	assert.NotNil(t, err)
+}
+
+// named parameters are encoded when the plan runs
+func TestJSONPlanParams(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	plan, err := q.Select("_id").For("accounts").Where([]Condition{
+		JSONContains("metadata", Param("doc")),
+		{Field: "metadata", Type: ConditionJSON, Operator: "?|", Values: []any{Param("keys")}},
+	}).Compile()
+	assert.Nil(t, err)
+
+	assert.Equal(t, `SELECT _id FROM accounts WHERE metadata @> $1::jsonb AND metadata ?| $2::text[];`, plan.SQL())
+	assert.Equal(t, []string{"doc", "keys"}, plan.Params())
+	assert.Equal(t, []any{Param("doc"), Param("keys")}, plan.Args())
+
+	mock.ExpectQuery(`SELECT _id FROM accounts WHERE metadata @> $1::jsonb AND metadata ?| $2::text[];`).WithArgs(`{"plan":"pro"}`, `{"vip","partner"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow(int64(1)))
+
+	rows, err := plan.Find(map[string]any{"doc": map[string]any{"plan": "pro"}, "keys": []string{"vip", "partner"}})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{int64(1)}}, rows)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	_, err = plan.Find(map[string]any{"doc": "pro", "keys": "vip"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "parameter keys")
+}
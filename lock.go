@@ -0,0 +1,134 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// a FOR UPDATE / FOR SHARE clause of a select
+type lockClause struct {
+	strength string
+	tables   []string // OF tables, all tables of the query when empty
+	wait     string   // NOWAIT or SKIP LOCKED
+}
+
+// lock the selected rows against updates and deletes, optionally only the rows
+// of the given tables. Locking queries must run in a transaction.
+//
+//	q.Select().For("jobs").Where(...).Limit(10).ForUpdate().SkipLocked().InTx(tx).Find()
+func (q *query) ForUpdate(tables ...string) QueryExecutor {
+	return q.lock("UPDATE", tables)
+}
+
+// lock the selected rows against updates of their keys and deletes
+func (q *query) ForNoKeyUpdate(tables ...string) QueryExecutor {
+	return q.lock("NO KEY UPDATE", tables)
+}
+
+// lock the selected rows against updates and deletes, other transactions
+// may share the lock
+func (q *query) ForShare(tables ...string) QueryExecutor {
+	return q.lock("SHARE", tables)
+}
+
+// lock the selected rows against deletes and updates of their keys
+func (q *query) ForKeyShare(tables ...string) QueryExecutor {
+	return q.lock("KEY SHARE", tables)
+}
+
+func (q *query) lock(strength string, tables []string) QueryExecutor {
+	q = q.clone()
+	q.locks = append(q.locks, lockClause{strength: strength, tables: tables})
+	return q
+}
+
+// fail instead of waiting for rows locked by another transaction
+func (q *query) NoWait() QueryExecutor {
+	return q.lockWait("NOWAIT")
+}
+
+// skip rows locked by another transaction
+func (q *query) SkipLocked() QueryExecutor {
+	return q.lockWait("SKIP LOCKED")
+}
+
+// set the wait policy of the last locking clause
+func (q *query) lockWait(wait string) QueryExecutor {
+	q = q.clone()
+
+	if len(q.locks) == 0 {
+		q.errors = append(q.errors, fmt.Errorf("%s requires a locking clause", wait))
+		return q
+	}
+
+	last := &q.locks[len(q.locks)-1]
+	if last.wait != "" {
+		q.errors = append(q.errors, fmt.Errorf("%s conflicts with %s", wait, last.wait))
+	}
+
+	last.wait = wait
+	return q
+}
+
+// render the locking clauses
+func (q *query) addLocks() error {
+	if len(q.locks) == 0 {
+		return nil
+	}
+
+	// postgres cannot lock rows of aggregated or combined results
+	if q.isDistinct() || q.isGrouped() || len(q.setOps) > 0 {
+		err := fmt.Errorf("locking clauses not allowed with distinct, group by or set operations")
+		q.errors = append(q.errors, err)
+		return err
+	}
+
+	// tables of the from clause, by alias when they have one
+	tables := []string{q.table}
+	for _, join := range q.joins {
+		if join.Alias != "" {
+			tables = append(tables, join.Alias)
+		} else {
+			tables = append(tables, join.Table)
+		}
+	}
+
+	for _, lock := range q.locks {
+		clause := " FOR " + lock.strength
+
+		if len(lock.tables) > 0 {
+			of := make([]string, len(lock.tables))
+			for i, table := range lock.tables {
+				if !slices.Contains(tables, table) {
+					err := fmt.Errorf("locked table %q not in the query", table)
+					q.errors = append(q.errors, err)
+					return err
+				}
+
+				name, err := q.aliasIdent(table)
+				if err != nil {
+					q.errors = append(q.errors, err)
+					return err
+				}
+				of[i] = name
+			}
+			clause += " OF " + strings.Join(of, ", ")
+		}
+
+		if lock.wait != "" {
+			clause += " " + lock.wait
+		}
+
+		q.queryString += clause
+	}
+	return nil
+}
+
+// locking queries must run in a transaction, the locks are released when it ends
+func (q *query) checkLockTx() error {
+	if len(q.locks) > 0 && q.tx == nil {
+		return fmt.Errorf("locking clauses require a transaction")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRowLocks(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	// job queue, claim pending jobs other workers have not locked
+	jobs := q.Select("_id", "payload").For("jobs").Where([]Condition{Eq("status", "pending")}).
+		OrderBy([]OrderClause{{Field: "_id", Order: "ASC"}}).Limit(10).ForUpdate().SkipLocked()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT _id, payload FROM jobs WHERE  status = $1 ORDER BY _id ASC LIMIT $2 FOR UPDATE SKIP LOCKED;`).WithArgs(`pending`, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"_id", "payload"}).AddRow(int64(1), "a"))
+	mock.ExpectCommit()
+
+	tx, err := conn.GetDB().Begin()
+	assert.Nil(t, err)
+
+	rows, err := jobs.InTx(tx).Find()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{int64(1), "a"}}, rows)
+
+	assert.Nil(t, tx.Commit())
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// locking outside of a transaction
+	_, err = jobs.Find()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "locking clauses require a transaction")
+
+	_, err = jobs.Compile()
+	assert.NotNil(t, err)
+
+	// several locks, limited to tables of the query
+	sqlStr, _, err := NewBuilder().Select("jobs._id").For("jobs").Join([]JoinClause{
+		{JoinType: InnerJoin, Table: "workers", Alias: "w", Condition: Condition{Field: "jobs.worker_id", Operator: "=", Values: []any{Col("w._id")}}},
+	}).ForNoKeyUpdate("jobs").NoWait().ForKeyShare("w").ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT jobs._id FROM jobs INNER JOIN workers AS w ON jobs.worker_id = w._id FOR NO KEY UPDATE OF jobs NOWAIT FOR KEY SHARE OF w;`, sqlStr)
+
+	sqlStr, _, err = NewBuilder().Select().For("jobs").ForShare().ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT * FROM jobs FOR SHARE;`, sqlStr)
+
+	// failure cases
+	_, _, err = NewBuilder().Select().For("jobs").ForUpdate("workers").ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not in the query")
+
+	_, _, err = NewBuilder().Select().For("jobs").SkipLocked().ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().Select().For("jobs").ForUpdate().NoWait().SkipLocked().ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().Select("status").For("jobs").GroupBy([]string{"status"}).ForUpdate().ToSQL()
+	assert.NotNil(t, err)
+}
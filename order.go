@@ -0,0 +1,160 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// direction of an order by clause
+type SortDirection string
+
+const (
+	Asc  SortDirection = "ASC"
+	Desc SortDirection = "DESC"
+)
+
+// placement of nulls in an order by clause, postgres defaults to nulls last
+// ascending and first descending
+type NullsOrder string
+
+const (
+	NullsDefault NullsOrder = ""
+	NullsFirst   NullsOrder = "FIRST"
+	NullsLast    NullsOrder = "LAST"
+)
+
+// check if nulls sort before the values
+func (o OrderClause) nullsFirst(desc bool) bool {
+	switch o.Nulls {
+	case NullsFirst:
+		return true
+	case NullsLast:
+		return false
+	default:
+		return desc
+	}
+}
+
+// operators allowed in expressions, e.g. distance operators for ordering
+var exprOperators = map[string]bool{
+	"+":   true,
+	"-":   true,
+	"*":   true,
+	"/":   true,
+	"%":   true,
+	"<->": true,
+	"<#>": true,
+	"<=>": true,
+	"<+>": true,
+}
+
+// a column combined with a bound value, e.g. embedding <-> $1
+type opExpr struct {
+	field string
+	op    string
+	value any
+}
+
+// expression of a column, an operator and a value bound as a placeholder
+//
+//	OrderBy([]OrderClause{{Expr: Op("embedding", "<->", vector), Order: Asc}})
+func Op(field, op string, value any) Expr {
+	return opExpr{field: field, op: op, value: value}
+}
+
+func (e opExpr) buildExpr(q *query) (string, error) {
+	op := strings.TrimSpace(e.op)
+	if !exprOperators[op] {
+		return "", fmt.Errorf("invalid expression operator %q", e.op)
+	}
+
+	field, err := q.fieldIdent(e.field)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := q.bindValue(e.value)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s %s %s", field, op, value), nil
+}
+
+// check if the select list expands a *, e.g. SELECT * or SELECT people.*
+func (q *query) selectsStar() bool {
+	if len(q.selects) == 0 {
+		return true
+	}
+
+	for _, col := range q.selects {
+		if c, ok := col.(selectColumn); ok && (c == "*" || strings.HasSuffix(string(c), ".*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// render the sort key of an order by clause
+func (q *query) orderKey(order OrderClause) (string, error) {
+	keys := 0
+	for _, set := range []bool{order.Field != "", order.Expr != nil, order.Position != 0} {
+		if set {
+			keys++
+		}
+	}
+
+	if keys != 1 {
+		return "", fmt.Errorf("order condition invalid, requires one of field, expression or position")
+	}
+
+	switch {
+	case order.Expr != nil:
+		return order.Expr.buildExpr(q)
+	case order.Position != 0:
+		// positions refer to the select list, its length is unknown with a *
+		if order.Position < 0 || (!q.selectsStar() && order.Position > len(q.selects)) {
+			return "", fmt.Errorf("order position %d out of range", order.Position)
+		}
+		return fmt.Sprintf("%d", order.Position), nil
+	default:
+		return q.fieldIdent(order.Field)
+	}
+}
+
+// render a single order by term
+func (q *query) orderTerm(order OrderClause) (string, error) {
+	if order.Order == "" {
+		return "", fmt.Errorf("order condition invalid")
+	}
+
+	key, err := q.orderKey(order)
+	if err != nil {
+		return "", err
+	}
+
+	term := key
+
+	if order.Collate != "" {
+		if !collationRe.MatchString(order.Collate) {
+			return "", fmt.Errorf("invalid collation %q", order.Collate)
+		}
+		term += " COLLATE " + quoteIdent(order.Collate)
+	}
+
+	direction, err := normalizeDirection(order.Order)
+	if err != nil {
+		return "", err
+	}
+	term += " " + string(direction)
+
+	switch order.Nulls {
+	case NullsDefault:
+	case NullsFirst, NullsLast:
+		term += " NULLS " + string(order.Nulls)
+	default:
+		return "", fmt.Errorf("invalid nulls order %q", order.Nulls)
+	}
+
+	return term, nil
+}
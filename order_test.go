@@ -0,0 +1,65 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBy(t *testing.T) {
+	// nulls placement and collation
+	sqlStr, _, err := NewBuilder().Select("_id", "name").For("people").OrderBy([]OrderClause{
+		{Field: "name", Order: Asc, Collate: "en_US", Nulls: NullsLast},
+		{Field: "_id", Order: Desc, Nulls: NullsFirst},
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id, name FROM people ORDER BY name COLLATE "en_US" ASC NULLS LAST, _id DESC NULLS FIRST;`, sqlStr)
+
+	// parameterized expressions bind after the where values
+	sqlStr, args, err := NewBuilder().Select("_id").For("documents").Where([]Condition{Eq("owner", "ops")}).OrderBy([]OrderClause{
+		{Expr: Op("embedding", "<->", "[1,2,3]"), Order: Asc},
+	}).Limit(5).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM documents WHERE  owner = $1 ORDER BY embedding <-> $2 ASC LIMIT $3;`, sqlStr)
+	assert.Equal(t, []any{"ops", "[1,2,3]", 5}, args)
+
+	// select aliases and positions
+	sqlStr, _, err = NewBuilder().Select("location", "COUNT(*) AS total").For("people").GroupBy([]string{"location"}).OrderBy([]OrderClause{
+		{Field: "total", Order: Desc},
+		{Position: 1, Order: "asc"},
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT location, COUNT(*) AS total FROM people GROUP BY location ORDER BY total DESC, 1 ASC;`, sqlStr)
+
+	// positions past the listed columns are left to the database with a *
+	sqlStr, _, err = NewBuilder().Select().For("people").OrderBy([]OrderClause{{Position: 2, Order: Desc}}).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT * FROM people ORDER BY 2 DESC;`, sqlStr)
+
+	sqlStr, _, err = NewBuilder().Select("_id", "people.*").For("people").OrderBy([]OrderClause{{Position: 4, Order: Asc}}).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id, people.* FROM people ORDER BY 4 ASC;`, sqlStr)
+
+	// failure cases
+	invalid := []OrderClause{
+		{Field: "name", Order: "ASC; DROP TABLE people"},
+		{Field: "name", Order: Asc, Nulls: "MIDDLE"},
+		{Field: "name", Order: Asc, Collate: `C"; DROP`},
+		{Field: "name", Position: 1, Order: Asc},
+		{Order: Asc},
+		{Position: 3, Order: Asc},
+		{Expr: Op("embedding", "; DROP", 1), Order: Asc},
+		{Field: "name"},
+	}
+
+	for _, order := range invalid {
+		_, _, err = NewBuilder().Select("_id", "name").For("people").OrderBy([]OrderClause{order}).ToSQL()
+		assert.NotNil(t, err, "%+v", order)
+	}
+
+	_, _, err = NewBuilder().SelectExpr(RowNumber().Over(Window{OrderBy: []OrderClause{{Position: 1, Order: Asc}}})).For("people").ToSQL()
+	assert.NotNil(t, err)
+}
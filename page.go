@@ -0,0 +1,87 @@
+package database
+
+import (
+	"fmt"
+)
+
+// largest page FindPage returns unless configured with WithMaxPageSize
+const DefaultMaxPageSize = 500
+
+// a page of offset pagination
+type Page struct {
+	Items    [][]any
+	Total    int64 // rows matching the query across all pages
+	Page     int   // 1 based page number
+	PageSize int
+}
+
+// limit the page size accepted by FindPage, defaults to DefaultMaxPageSize
+func WithMaxPageSize(size int) QueryOption {
+	return func(q *query) {
+		q.maxPageSize = size
+	}
+}
+
+// build a count of the rows the select returns. Grouped, distinct and limited
+// queries are counted through a sub query so each group or distinct row counts
+// once and the limits apply, the order is kept only to pick the limited rows.
+func (q *query) buildCount() error {
+	c := q.fresh()
+	c.locks = nil
+
+	limited := c.limit != nil || c.offset != nil
+	if !limited {
+		c.orderBy = nil
+		c.withTies = false
+	}
+
+	if !limited && !c.isGrouped() && len(c.setOps) == 0 && !c.isDistinct() {
+		c.selects = []Expr{selectColumn("COUNT(*)")}
+
+		err := c.buildSelect()
+		if err != nil {
+			return err
+		}
+
+		q.queryString, q.args, q.argCount = c.queryString, c.args, c.argCount
+		return nil
+	}
+
+	err := c.buildSelect()
+	if err != nil {
+		return err
+	}
+
+	q.queryString = fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS counted", c.queryString)
+	q.args, q.argCount = c.args, c.argCount
+	return nil
+}
+
+// fetch a 1 based page of the select query along with the total number of
+// matching rows, counted over the same joins and conditions
+func (q *query) FindPage(page, size int) (*Page, error) {
+	maxSize := q.maxPageSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxPageSize
+	}
+
+	if page < 1 {
+		return nil, fmt.Errorf("invalid page %d", page)
+	}
+
+	if size < 1 || size > maxSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d", maxSize)
+	}
+
+	items, err := q.Limit(size).Offset((page - 1) * size).Find()
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := q.NoLimit().NoOffset().Count()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{Items: items, Total: total, Page: page, PageSize: size}, nil
+}
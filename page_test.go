@@ -0,0 +1,59 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestFindPage(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn, WithMaxPageSize(50))
+	assert.Nil(t, err)
+
+	people := q.Select("people._id", "people.name").For("people").Join([]JoinClause{
+		{JoinType: InnerJoin, Table: "teams", Condition: Condition{Field: "people.team_id", Operator: "=", Values: []any{Col("teams._id")}}},
+	}).Where([]Condition{Eq("teams.name", "ops")}).OrderBy([]OrderClause{{Field: "people.name", Order: "ASC"}})
+
+	// the count drops the order and limits but keeps joins and conditions
+	mock.ExpectQuery(`SELECT people._id, people.name FROM people INNER JOIN teams ON people.team_id = teams._id WHERE  teams.name = $1 ORDER BY people.name ASC LIMIT $2 OFFSET $3;`).WithArgs(`ops`, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"_id", "name"}).AddRow(int64(3), "carol").AddRow(int64(4), "dave"))
+	mock.ExpectQuery(`SELECT COUNT(*) FROM people INNER JOIN teams ON people.team_id = teams._id WHERE  teams.name = $1;`).WithArgs(`ops`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(5)))
+
+	page, err := people.FindPage(2, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, &Page{Items: [][]any{{int64(3), "carol"}, {int64(4), "dave"}}, Total: 5, Page: 2, PageSize: 2}, page)
+
+	// grouped queries count their groups
+	teams := q.Select("team_id", "COUNT(*)").For("people").GroupBy([]string{"team_id"})
+
+	mock.ExpectQuery(`SELECT team_id, COUNT(*) FROM people GROUP BY team_id LIMIT $1 OFFSET $2;`).WithArgs(10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"team_id", "count"}).AddRow(int64(1), int64(3)))
+	mock.ExpectQuery(`SELECT COUNT(*) FROM (SELECT team_id, COUNT(*) FROM people GROUP BY team_id) AS counted;`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
+
+	page, err = teams.FindPage(1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), page.Total)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// page guards
+	_, err = people.FindPage(0, 10)
+	assert.NotNil(t, err)
+
+	_, err = people.FindPage(1, 51)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "page size must be between 1 and 50")
+}
@@ -0,0 +1,288 @@
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// a page of keyset pagination
+type KeysetPage struct {
+	Rows [][]any
+	Next string // cursor of the following page, empty on the last page
+	Prev string // cursor of the preceding page, empty on the first page
+}
+
+// decoded pagination cursor
+type seekCursor struct {
+	Backward bool     `json:"b,omitempty"` // page before the keys
+	Fields   []string `json:"f"`           // order by fields the keys belong to
+	Keys     []any    `json:"k"`
+}
+
+func encodeCursor(c seekCursor) (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("could not encode cursor => %s", err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(token string) (seekCursor, error) {
+	var c seekCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor")
+	}
+
+	// numbers are kept as their text so large ids survive the round trip
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil {
+		return c, fmt.Errorf("invalid cursor")
+	}
+
+	return c, nil
+}
+
+// the condition a key must satisfy to sort after value
+func seekAfter(order OrderClause, desc bool, value any) (Condition, bool) {
+	nullsFirst := order.nullsFirst(desc)
+
+	// every value sorts after a leading null, nothing after a trailing one
+	if value == nil {
+		if nullsFirst {
+			return Condition{Field: order.Field, Operator: "IS DISTINCT FROM", Values: []any{nil}}, true
+		}
+		return Condition{}, false
+	}
+
+	after := Gt(order.Field, value)
+	if desc {
+		after = Lt(order.Field, value)
+	}
+
+	if order.Nullable && !nullsFirst {
+		return Or(after, Condition{Field: order.Field, Operator: "IS NOT DISTINCT FROM", Values: []any{nil}}), true
+	}
+	return after, true
+}
+
+// build the predicate selecting rows strictly after the keys, false when no
+// row can sort after them
+func seekCondition(orders []OrderClause, desc []bool, keys []any) (Condition, bool) {
+	// a row comparison when every key sorts the same way and none is nullable
+	uniform := true
+	for i, order := range orders {
+		if order.Nullable || desc[i] != desc[0] || keys[i] == nil {
+			uniform = false
+		}
+	}
+
+	if uniform {
+		fields := make([]string, len(orders))
+		for i, order := range orders {
+			fields[i] = order.Field
+		}
+
+		op := ">"
+		if desc[0] {
+			op = "<"
+		}
+		return Tuple(fields, op, keys...), true
+	}
+
+	// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+	var branches []Condition
+	for i := range orders {
+		after, ok := seekAfter(orders[i], desc[i], keys[i])
+		if !ok {
+			continue
+		}
+
+		var branch []Condition
+		for j := 0; j < i; j++ {
+			op := "="
+			if orders[j].Nullable || keys[j] == nil {
+				op = "IS NOT DISTINCT FROM"
+			}
+			branch = append(branch, Condition{Field: orders[j].Field, Operator: op, Values: []any{keys[j]}})
+		}
+		if len(branch) == 0 {
+			branches = append(branches, after)
+		} else {
+			branches = append(branches, And(append(branch, after)...))
+		}
+	}
+
+	if len(branches) == 0 {
+		return Condition{}, false
+	}
+
+	return Or(branches...), true
+}
+
+// find the result column of an order by field
+func keyColumn(cols []string, field string) (int, error) {
+	name := field[strings.LastIndex(field, ".")+1:]
+	idx := slices.Index(cols, name)
+	if idx == -1 {
+		return 0, fmt.Errorf("order by field %s must be selected for pagination", field)
+	}
+	return idx, nil
+}
+
+// key values of a row, bytes are kept as text so they encode cleanly
+func rowKeys(row []any, idx []int) []any {
+	keys := make([]any, len(idx))
+	for i, c := range idx {
+		if b, ok := row[c].([]byte); ok {
+			keys[i] = string(b)
+		} else {
+			keys[i] = row[c]
+		}
+	}
+	return keys
+}
+
+// keyset pagination over the order by clauses, pass an empty cursor for the
+// first page and the Next / Prev cursor of a page to move from it. The order
+// by should end on a unique column so every row has a distinct position.
+func (q *query) Paginate(cursor string, size int) (*KeysetPage, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid page size %d", size)
+	}
+
+	if len(q.orderBy) == 0 {
+		return nil, fmt.Errorf("pagination requires order by clauses")
+	}
+
+	// the seek condition would only filter the first query
+	if len(q.setOps) > 0 {
+		return nil, fmt.Errorf("keyset pagination over set operations not supported")
+	}
+
+	fields := make([]string, len(q.orderBy))
+	desc := make([]bool, len(q.orderBy))
+	for i, order := range q.orderBy {
+		if order.Field == "" {
+			return nil, fmt.Errorf("pagination requires order by fields")
+		}
+
+		direction, err := normalizeDirection(order.Order)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = order.Field
+		desc[i] = direction == Desc
+	}
+
+	var seek seekCursor
+	if cursor != "" {
+		var err error
+		seek, err = decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Equal(seek.Fields, fields) || len(seek.Keys) != len(fields) {
+			return nil, fmt.Errorf("cursor does not match the order by clauses")
+		}
+	}
+
+	// a backward page walks the order reversed, then flips the rows back
+	orders := make([]OrderClause, len(q.orderBy))
+	for i, order := range q.orderBy {
+		orders[i] = order
+		if seek.Backward {
+			desc[i] = !desc[i]
+
+			// explicit null placement flips with the direction
+			switch order.Nulls {
+			case NullsFirst:
+				orders[i].Nulls = NullsLast
+			case NullsLast:
+				orders[i].Nulls = NullsFirst
+			}
+		}
+
+		orders[i].Order = Asc
+		if desc[i] {
+			orders[i].Order = Desc
+		}
+	}
+
+	c := q.clone()
+	c.orderBy = orders
+	c = c.Limit(size + 1).NoOffset().(*query)
+
+	if cursor != "" {
+		cond, ok := seekCondition(orders, desc, seek.Keys)
+		if !ok {
+			return &KeysetPage{}, nil
+		}
+
+		if len(c.whereConds) > 0 {
+			c.whereConds = []Condition{{Nested: &WhereGroup{Conditions: c.whereConds}, NextLogicalOp: "AND"}, cond}
+		} else {
+			c.whereConds = []Condition{cond}
+		}
+	}
+
+	db, queryString, args, err := c.prepareSelect()
+	if err != nil {
+		return nil, err
+	}
+
+	cols, rows, err := queryColumnRows(c.context(), db, queryString, args)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := make([]int, len(fields))
+	for i, field := range fields {
+		idx[i], err = keyColumn(cols, field)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	more := len(rows) > size
+	if more {
+		rows = rows[:size]
+	}
+
+	if seek.Backward {
+		slices.Reverse(rows)
+	}
+
+	page := &KeysetPage{Rows: rows}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	// rows before the page exist when walking forward from a cursor, or when
+	// a backward walk found more rows
+	hasPrev := (!seek.Backward && cursor != "") || (seek.Backward && more)
+	hasNext := (!seek.Backward && more) || seek.Backward
+
+	if hasPrev {
+		page.Prev, err = encodeCursor(seekCursor{Backward: true, Fields: fields, Keys: rowKeys(rows[0], idx)})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hasNext {
+		page.Next, err = encodeCursor(seekCursor{Fields: fields, Keys: rowKeys(rows[len(rows)-1], idx)})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestKeysetPagination(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	audit := q.Select("_id", "created_at").For("audit").Where([]Condition{Eq("actor", "jpomfrette")}).OrderBy([]OrderClause{
+		{Field: "created_at", Order: "DESC"},
+		{Field: "_id", Order: "DESC"},
+	})
+
+	cols := []string{"_id", "created_at"}
+
+	// first page, one extra row tells there is a next page
+	mock.ExpectQuery(`SELECT _id, created_at FROM audit WHERE  actor = $1 ORDER BY created_at DESC, _id DESC LIMIT $2;`).WithArgs(`jpomfrette`, 3).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(int64(9), "2024-03-03").AddRow(int64(8), "2024-03-02").AddRow(int64(7), "2024-03-01"))
+
+	page, err := audit.Paginate("", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{int64(9), "2024-03-03"}, {int64(8), "2024-03-02"}}, page.Rows)
+	assert.Empty(t, page.Prev)
+	assert.NotEmpty(t, page.Next)
+
+	// next page seeks past the last row
+	mock.ExpectQuery(`SELECT _id, created_at FROM audit WHERE (  actor = $1 ) AND (created_at, _id) < ($2, $3) ORDER BY created_at DESC, _id DESC LIMIT $4;`).WithArgs(`jpomfrette`, `2024-03-02`, `8`, 3).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(int64(7), "2024-03-01"))
+
+	page, err = audit.Paginate(page.Next, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{int64(7), "2024-03-01"}}, page.Rows)
+	assert.NotEmpty(t, page.Prev)
+	assert.Empty(t, page.Next)
+
+	// previous page walks the order reversed and flips the rows back
+	mock.ExpectQuery(`SELECT _id, created_at FROM audit WHERE (  actor = $1 ) AND (created_at, _id) > ($2, $3) ORDER BY created_at ASC, _id ASC LIMIT $4;`).WithArgs(`jpomfrette`, `2024-03-01`, `7`, 3).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(int64(8), "2024-03-02").AddRow(int64(9), "2024-03-03"))
+
+	page, err = audit.Paginate(page.Prev, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{int64(9), "2024-03-03"}, {int64(8), "2024-03-02"}}, page.Rows)
+	assert.Empty(t, page.Prev)
+	assert.NotEmpty(t, page.Next)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// cursors of another ordering are rejected
+	_, err = audit.OrderBy([]OrderClause{{Field: "_id", Order: "ASC"}}).Paginate(page.Next, 2)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cursor does not match")
+
+	_, err = audit.Paginate("not a cursor", 2)
+	assert.NotNil(t, err)
+}
+
+func TestKeysetMixedOrder(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	people := q.Select("_id", "location", "shift_type").For("people").OrderBy([]OrderClause{
+		{Field: "location", Order: "ASC", Nullable: true},
+		{Field: "shift_type", Order: "DESC"},
+		{Field: "_id", Order: "ASC"},
+	})
+
+	cols := []string{"_id", "location", "shift_type"}
+
+	mock.ExpectQuery(`SELECT _id, location, shift_type FROM people ORDER BY location ASC, shift_type DESC, _id ASC LIMIT $1;`).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(int64(3), "CN", "nocturnal").AddRow(int64(4), "FR", "diurnal"))
+
+	page, err := people.Paginate("", 1)
+	assert.Nil(t, err)
+	assert.Len(t, page.Rows, 1)
+
+	// a nullable ascending key also matches the nulls sorted after it
+	mock.ExpectQuery(`SELECT _id, location, shift_type FROM people WHERE ( (  location > $1 OR  location IS NOT DISTINCT FROM $2 ) OR (  location IS NOT DISTINCT FROM $3 AND  shift_type < $4 ) OR (  location IS NOT DISTINCT FROM $5 AND  shift_type = $6 AND  _id > $7 ) ) ORDER BY location ASC, shift_type DESC, _id ASC LIMIT $8;`).
+		WithArgs(`CN`, nil, `CN`, `nocturnal`, `CN`, `nocturnal`, `3`, 2).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(int64(5), nil, "diurnal"))
+
+	page, err = people.Paginate(page.Next, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{int64(5), nil, "diurnal"}}, page.Rows)
+	assert.Empty(t, page.Next)
+
+	// the null key sorts last, only ties on it can follow
+	mock.ExpectQuery(`SELECT _id, location, shift_type FROM people WHERE ( (  location IS NOT DISTINCT FROM $1 AND  shift_type < $2 ) OR (  location IS NOT DISTINCT FROM $3 AND  shift_type = $4 AND  _id > $5 ) ) ORDER BY location ASC, shift_type DESC, _id ASC LIMIT $6;`).
+		WithArgs(nil, `diurnal`, nil, `diurnal`, `5`, 2).
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	next, err := encodeCursor(seekCursor{Fields: []string{"location", "shift_type", "_id"}, Keys: []any{nil, "diurnal", 5}})
+	assert.Nil(t, err)
+
+	page, err = people.Paginate(next, 1)
+	assert.Nil(t, err)
+	assert.Empty(t, page.Rows)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestKeysetExplicitNulls(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	people := q.Select("_id", "location").For("people").OrderBy([]OrderClause{
+		{Field: "location", Order: Asc, Nulls: NullsFirst, Nullable: true},
+		{Field: "_id", Order: Asc},
+	})
+
+	cols := []string{"_id", "location"}
+
+	// leading nulls are followed by every value
+	mock.ExpectQuery(`SELECT _id, location FROM people WHERE (  location IS DISTINCT FROM $1 OR (  location IS NOT DISTINCT FROM $2 AND  _id > $3 ) ) ORDER BY location ASC NULLS FIRST, _id ASC LIMIT $4;`).
+		WithArgs(nil, nil, `5`, 2).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(int64(6), "CN"))
+
+	next, err := encodeCursor(seekCursor{Fields: []string{"location", "_id"}, Keys: []any{nil, 5}})
+	assert.Nil(t, err)
+
+	page, err := people.Paginate(next, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{int64(6), "CN"}}, page.Rows)
+
+	// walking back flips the null placement with the direction, the leading
+	// nulls now sort last and follow every value
+	mock.ExpectQuery(`SELECT _id, location FROM people WHERE ( (  location < $1 OR  location IS NOT DISTINCT FROM $2 ) OR (  location IS NOT DISTINCT FROM $3 AND  _id < $4 ) ) ORDER BY location DESC NULLS LAST, _id DESC LIMIT $5;`).
+		WithArgs(`CN`, nil, `CN`, `6`, 2).
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	_, err = people.Paginate(page.Prev, 1)
+	assert.Nil(t, err)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
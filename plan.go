@@ -0,0 +1,201 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+)
+
+// Param is a named placeholder, its value is supplied when a Plan is executed
+// e.g. Where([]Condition{Eq("location", Param("location"))})
+type Param string
+
+// named parameter whose value is encoded for its operator when the plan runs,
+// e.g. json documents or arrays
+type encodedParam struct {
+	name   Param
+	encode func(value any) (any, error)
+}
+
+// name of the parameter held by a bound argument
+func paramName(arg any) (Param, bool) {
+	switch p := arg.(type) {
+	case Param:
+		return p, true
+	case encodedParam:
+		return p.name, true
+	}
+	return "", false
+}
+
+// Plan is a compiled statement. It never changes after Compile, so a single
+// plan can be executed from many goroutines at once, each with its own values
+// for the named parameters.
+type Plan struct {
+	conn ConnectionExecutor
+	tx   *sql.Tx
+	kind StatementKind
+	sql  string
+	args []any
+}
+
+// compile the query into an immutable plan
+func (q *query) Compile() (*Plan, error) {
+	// plans run outside of any transaction
+	if len(q.locks) > 0 {
+		return nil, fmt.Errorf("locking clauses cannot be compiled into a plan")
+	}
+
+	sqlStr, args, err := q.ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Plan{conn: q.conn, kind: q.kind, sql: sqlStr, args: args}, nil
+}
+
+// the rendered statement
+func (p *Plan) SQL() string {
+	return p.sql
+}
+
+// the bound arguments, named parameters are left as Param values
+func (p *Plan) Args() []any {
+	args := slices.Clone(p.args)
+	for i, arg := range args {
+		if param, ok := paramName(arg); ok {
+			args[i] = param
+		}
+	}
+	return args
+}
+
+// names of the parameters the plan expects
+func (p *Plan) Params() []string {
+	var names []string
+	for _, arg := range p.args {
+		if param, ok := paramName(arg); ok && !slices.Contains(names, string(param)) {
+			names = append(names, string(param))
+		}
+	}
+	return names
+}
+
+// resolve named parameters into the positional argument list
+func bindParams(args []any, values map[string]any) ([]any, error) {
+	bound := make([]any, len(args))
+	known := make(map[string]bool)
+
+	for i, arg := range args {
+		param, ok := paramName(arg)
+		if !ok {
+			bound[i] = arg
+			continue
+		}
+
+		value, ok := values[string(param)]
+		if !ok {
+			return nil, fmt.Errorf("missing value for parameter %s", param)
+		}
+
+		if enc, ok := arg.(encodedParam); ok {
+			var err error
+			value, err = enc.encode(value)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s => %s", param, err.Error())
+			}
+		}
+
+		known[string(param)] = true
+		bound[i] = value
+	}
+
+	for name := range values {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+	}
+
+	return bound, nil
+}
+
+// copy of the plan running inside the transaction, the plan itself is left
+// untouched so it can still be shared
+func (p *Plan) InTx(tx *sql.Tx) *Plan {
+	c := *p
+	c.tx = tx
+	return &c
+}
+
+// the transaction of the plan when set, otherwise the db instance
+func (p *Plan) runner() (runner, error) {
+	if p.tx != nil {
+		return p.tx, nil
+	}
+
+	if p.conn == nil || p.conn.GetDB() == nil {
+		return nil, fmt.Errorf("could not find connection")
+	}
+	return p.conn.GetDB(), nil
+}
+
+// run a select plan with the given parameter values
+func (p *Plan) Find(values map[string]any) ([][]any, error) {
+	return p.FindContext(context.Background(), values)
+}
+
+// run a select plan with the given parameter values, cancelling the context
+// stops the query
+func (p *Plan) FindContext(ctx context.Context, values map[string]any) ([][]any, error) {
+	if p.kind != SelectStatement {
+		return nil, fmt.Errorf("plan is not a select statement")
+	}
+
+	db, err := p.runner()
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := bindParams(p.args, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return queryRows(ctx, db, p.sql, args)
+}
+
+// run an insert, update or delete plan with the given parameter values
+func (p *Plan) Exec(values map[string]any) (int64, error) {
+	return p.ExecContext(context.Background(), values)
+}
+
+// run an insert, update or delete plan with the given parameter values,
+// cancelling the context stops the statement
+func (p *Plan) ExecContext(ctx context.Context, values map[string]any) (int64, error) {
+	if p.kind == SelectStatement {
+		return 0, fmt.Errorf("plan is a select statement")
+	}
+
+	db, err := p.runner()
+	if err != nil {
+		return 0, err
+	}
+
+	args, err := bindParams(p.args, values)
+	if err != nil {
+		return 0, err
+	}
+
+	var res sql.Result
+	if p.tx != nil {
+		res, err = db.ExecContext(ctx, p.sql, args...)
+	} else {
+		res, err = execStatement(ctx, p.conn, p.sql, args)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// run with -race, the plan and the base query are shared by every goroutine
+func TestConcurrentPlan(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+	mock.MatchExpectationsInOrder(false)
+
+	defer conn.Close()
+
+	base, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	plan, err := base.Select("_id").For("people").Where([]Condition{
+		Eq("location", Param("location")),
+	}).Compile()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people WHERE  location = $1;`, plan.SQL())
+	assert.Equal(t, []string{"location"}, plan.Params())
+
+	const workers = 16
+	for i := 0; i < workers; i++ {
+		mock.ExpectQuery(plan.SQL()).WithArgs(fmt.Sprintf("L%d", i)).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{fmt.Sprint(i)}...))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			rows, err := plan.Find(map[string]any{"location": fmt.Sprintf("L%d", i)})
+			assert.Nil(t, err)
+			assert.Equal(t, [][]any{{fmt.Sprint(i)}}, rows)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// the plan keeps its placeholders after concurrent use
+	assert.Equal(t, []any{Param("location")}, plan.Args())
+}
+
+func TestConcurrentBuilders(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+	mock.MatchExpectationsInOrder(false)
+
+	defer conn.Close()
+
+	base, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	people := base.Select("_id").For("people")
+
+	const workers = 16
+	for i := 0; i < workers; i++ {
+		mock.ExpectQuery(`SELECT _id FROM people WHERE  _id = $1 AND  location = $2;`).WithArgs(fmt.Sprint(i), `FR`).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{fmt.Sprint(i)}...))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			rows, err := people.Where([]Condition{{Field: "_id", Operator: "=", Values: []any{fmt.Sprint(i)}, NextLogicalOp: "AND"}, Eq("location", "FR")}).Find()
+			assert.Nil(t, err)
+			assert.Len(t, rows, 1)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestPlanParams(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	plan, err := q.Set(map[string]any{"description": Param("description")}).For("fire_teams").Where([]Condition{
+		Eq("_id", "foxtrot"),
+	}).As(UpdateStatement).Compile()
+	assert.Nil(t, err)
+
+	mock.ExpectPrepare(`UPDATE fire_teams SET description = $1 WHERE  _id = $2;`).WillBeClosed()
+	mock.ExpectExec(`UPDATE fire_teams SET description = $1 WHERE  _id = $2;`).WithArgs(`Team 7`, `foxtrot`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	count, err := plan.Exec(map[string]any{"description": "Team 7"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = plan.Exec(nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "missing value for parameter description")
+
+	_, err = plan.Exec(map[string]any{"description": "Team 7", "name": "x"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown parameter name")
+
+	_, err = plan.Find(nil)
+	assert.NotNil(t, err)
+
+	// parameters must be supplied through a plan
+	_, err = q.Select().For("people").Where([]Condition{Eq("location", Param("location"))}).Find()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "missing value for parameter location")
+}
+
+func TestPlanContextAndTx(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	plan, err := q.For("audit").Where([]Condition{Eq("actor", Param("actor"))}).As(DeleteStatement).Compile()
+	assert.Nil(t, err)
+
+	// the plan runs in the transaction, the shared plan is left untouched
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM audit WHERE  actor = $1;`).WithArgs(`jpomfrette`).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectRollback()
+
+	tx, err := conn.GetDB().Begin()
+	assert.Nil(t, err)
+
+	count, err := plan.InTx(tx).ExecContext(context.Background(), map[string]any{"actor": "jpomfrette"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Nil(t, plan.tx)
+
+	assert.Nil(t, tx.Rollback())
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	// a cancelled context stops the query
+	find, err := q.Select("_id").For("audit").Where([]Condition{Eq("actor", Param("actor"))}).Compile()
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = find.FindContext(ctx, map[string]any{"actor": "jpomfrette"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,13 +1,18 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"strconv"
+	"iter"
 	"strings"
 )
 
+// QueryExecutor builds and runs statements. Every builder method returns a copy
+// and builds never modify the query, so a query may be shared between goroutines.
 type QueryExecutor interface {
 	Select(cols ...string) QueryExecutor
+	SelectExpr(exprs ...Expr) QueryExecutor
 	For(table string) QueryExecutor
 	Where(conditions []Condition) QueryExecutor
 
@@ -15,20 +20,64 @@ type QueryExecutor interface {
 
 	OrderBy(orderBy []OrderClause) QueryExecutor
 	GroupBy(groupBy []string) QueryExecutor
+	GroupByExpr(exprs ...Expr) QueryExecutor
 	Having(having []Condition) QueryExecutor
 
 	Limit(limit int) QueryExecutor
 	Offset(offset int) QueryExecutor
+	LimitWithTies(limit int) QueryExecutor
+	NoLimit() QueryExecutor
+	NoOffset() QueryExecutor
 	Set(values map[string]any) QueryExecutor
 
 	Find() ([][]any, error) // select query to be executed
 	FindOne(id string) ([]any, error)
 
+	WithContext(ctx context.Context) QueryExecutor
+	InTx(tx *sql.Tx) QueryExecutor
+	Cursor(opts CursorOptions) iter.Seq2[Row, error]       // server side cursor, see cursor.go
+	Paginate(cursor string, size int) (*KeysetPage, error) // keyset pagination, see paginate.go
+	With(name string, sub QueryExecutor) QueryExecutor     // common table expression, see cte.go
+	WithRecursive(name string, anchor, recursive QueryExecutor) QueryExecutor
+	Returning(cols ...string) QueryExecutor
+	ExecReturning() ([][]any, error)
+	Window(name string, window Window) QueryExecutor // named window, see window.go
+	Distinct() QueryExecutor
+	DistinctOn(cols ...string) QueryExecutor
+	ForUpdate(tables ...string) QueryExecutor // row locks, see lock.go
+	ForNoKeyUpdate(tables ...string) QueryExecutor
+	ForShare(tables ...string) QueryExecutor
+	ForKeyShare(tables ...string) QueryExecutor
+	NoWait() QueryExecutor
+	SkipLocked() QueryExecutor
+	Union(queries ...QueryExecutor) QueryExecutor // set operations, see setop.go
+	UnionAll(queries ...QueryExecutor) QueryExecutor
+	Intersect(queries ...QueryExecutor) QueryExecutor
+	Except(queries ...QueryExecutor) QueryExecutor
+	FindPage(page, size int) (*Page, error)            // offset pagination with a total count
+	QueryRaw(sql string, args ...any) ([][]any, error) // raw statements, see raw.go
+	Exec(sql string, args ...any) (int64, error)
+	Count() (int64, error)
+	Exists() (bool, error)
+	Sum(col string, dest any) error
+	Avg(col string, dest any) error
+	Min(col string, dest any) error
+	Max(col string, dest any) error
+	Pluck(col string) ([]any, error)
+	Rows() iter.Seq2[Row, error] // streams the select query, see stream.go
+	Each(fn func(Row) error) error
+
 	Create() (int64, error)
 	Update() (int64, error)
 
 	Delete() (int64, error)
 	DeleteOne(d string) (int64, error)
+
+	As(kind StatementKind) QueryExecutor
+	ToSQL() (string, []any, error) // render the statement without executing it
+
+	Clone() QueryExecutor
+	Compile() (*Plan, error) // immutable plan, see plan.go
 }
 
 type WhereGroup struct {
@@ -41,11 +90,17 @@ const (
 	ConditionStandard ConditionType = iota
 	ConditionIn
 	ConditionBetween
+	ConditionTuple      // row comparison, Field is a comma separated column list
+	ConditionJSON       // jsonb operator, the value is encoded for the operator, see json.go
+	ConditionArray      // array operator, the value is bound as a single array, see array.go
+	ConditionTextSearch // full text match, Values holds a TextSearch, see search.go
+	ConditionRaw        // raw sql predicate held in Expr, see raw.go
 )
 
 // Struct for a WHERE condition
 type Condition struct {
 	Field         string
+	Expr          Expr // left hand side expression used instead of Field
 	Operator      string
 	Values        []interface{}
 	NextLogicalOp string
@@ -54,65 +109,155 @@ type Condition struct {
 	Type          ConditionType
 }
 
+// ColumnRef is a condition value that refers to a column instead of a bound parameter
+type ColumnRef string
+
+// Col marks a condition value as a column, e.g. Col("teams.id")
+func Col(name string) ColumnRef {
+	return ColumnRef(name)
+}
+
 type JoinType string
 
 const (
-	BasicJoin JoinType = "JOIN"
-	InnerJoin JoinType = "INNER JOIN"
-	OuterJoin JoinType = "OUT JOIN"
-	LeftJoin  JoinType = "LEFT JOIN"
-	RightJoin JoinType = "RIGHT JOIN"
-	FullJoin  JoinType = "FULL JOIN"
+	BasicJoin      JoinType = "JOIN"
+	InnerJoin      JoinType = "INNER JOIN"
+	OuterJoin      JoinType = "FULL OUTER JOIN"
+	LeftJoin       JoinType = "LEFT JOIN"
+	LeftOuterJoin  JoinType = "LEFT OUTER JOIN"
+	RightJoin      JoinType = "RIGHT JOIN"
+	RightOuterJoin JoinType = "RIGHT OUTER JOIN"
+	FullJoin       JoinType = "FULL JOIN"
+	CrossJoin      JoinType = "CROSS JOIN"
 )
 
 // Struct for a JOIN clause
 type JoinClause struct {
 	JoinType  JoinType
 	Table     string
+	Alias     string
 	Condition Condition
+
+	// multiple ON conditions, joined with AND unless NextLogicalOp is set
+	Conditions []Condition
+
+	// USING (cols) instead of ON conditions
+	Using []string
+
+	// NATURAL join, takes no ON / USING
+	Natural bool
+
+	// sub query joined as LATERAL (...) in place of Table, requires an Alias
+	Lateral QueryExecutor
+
+	// expression joined in place of Table, e.g. Raw("generate_series(1, ?)", 7), requires an Alias
+	Source Expr
 }
 
-// Struct for an ORDER BY clause
+// Struct for an ORDER BY clause, the sort key is one of Field, Expr or Position
 type OrderClause struct {
-	Field string
-	Order string
+	Field    string
+	Expr     Expr // e.g. Op("embedding", "<->", vector)
+	Position int  // 1 based index of a select column
+	Order    SortDirection
+	Nulls    NullsOrder
+	Collate  string
+
+	// the column may hold nulls, used by keyset pagination
+	Nullable bool
 }
 
 type query struct {
 	// handle to the db instance
 	conn ConnectionExecutor
 
-	table     string
-	cols      []string
-	colValues []interface{}
-
-	whereConds  []Condition
-	joins       []JoinClause
-	groupBy     []string
-	having      []Condition
-	orderBy     []OrderClause
-	limit       int
-	offset      int
-	Args        []any
-	ArgCount    int
-	QueryString string
+	// statement rendered by ToSQL
+	kind StatementKind
+
+	// context used when executing
+	ctx context.Context
+
+	// transaction the query runs in, if any
+	tx *sql.Tx
+
+	table   string
+	cols    []string // insert / update columns
+	selects []Expr
+
+	distinct   bool
+	distinctOn []string
+	locks      []lockClause
+	colValues  []interface{}
+
+	whereConds []Condition
+	joins      []JoinClause
+	groupBy    []string
+	groupExprs []Expr
+	having     []Condition
+	orderBy    []OrderClause
+	limit      *int // nil when unset
+	offset     *int
+	withTies   bool
+	ctes       []commonTable
+	setOps     []setOperation
+	windows    []namedWindow
+	returning  []string
+
+	// build state, only set on the fresh copy a build runs on
+	args        []any
+	argCount    int
+	queryString string
+
+	// identifier handling, see sanitize.go
+	quoteIdents bool
+	schema      Schema
+	maxPageSize int
+	tsConfig    string // default text search configuration
+	outerScope  map[string]string
+	aliases     []string
 
 	errors []error
 }
 
+// select columns, each one a column name (optionally "col AS alias") or an Expr
 func (q *query) Select(cols ...string) QueryExecutor {
-	colLen := len(cols)
-	if colLen != 0 {
-		colsArr := make([]string, len(cols))
-		copy(colsArr, cols)
-		q.cols = cols
-	} else {
-		q.cols = []string{"*"}
+	q = q.clone()
+
+	if len(cols) == 0 {
+		q.selects = []Expr{selectColumn("*")}
+		return q
+	}
+
+	q.selects = make([]Expr, len(cols))
+	for i, col := range cols {
+		q.selects[i] = selectColumn(col)
+	}
+	return q
+}
+
+// add expressions to the select columns, after those set by Select
+//
+//	q.Select("_id").SelectExpr(RowNumber().Over(w).As("position")).For("scores")
+func (q *query) SelectExpr(exprs ...Expr) QueryExecutor {
+	q = q.clone()
+
+	if len(exprs) == 0 {
+		q.errors = append(q.errors, fmt.Errorf("empty select expressions not allowed"))
+	}
+
+	for _, expr := range exprs {
+		if expr == nil {
+			q.errors = append(q.errors, fmt.Errorf("nil select expression not allowed"))
+			continue
+		}
+		q.selects = append(q.selects, expr)
 	}
 	return q
 }
 
 func (q *query) For(table string) QueryExecutor {
+	q = q.clone()
+
 	if table == "" {
 		q.errors = append(q.errors, fmt.Errorf("empty table not allowed"))
 	}
@@ -122,6 +267,8 @@ func (q *query) For(table string) QueryExecutor {
 }
 
 func (q *query) Where(conditions []Condition) QueryExecutor {
+	q = q.clone()
+
 	if len(conditions) == 0 {
 		q.errors = append(q.errors, fmt.Errorf("empty conditions not allowed"))
 	}
@@ -131,6 +278,8 @@ func (q *query) Where(conditions []Condition) QueryExecutor {
 }
 
 func (q *query) Join(joins []JoinClause) QueryExecutor {
+	q = q.clone()
+
 	if len(joins) == 0 {
 		q.errors = append(q.errors, fmt.Errorf("empty joins not allowed"))
 	}
@@ -140,6 +289,8 @@ func (q *query) Join(joins []JoinClause) QueryExecutor {
 }
 
 func (q *query) GroupBy(groupBy []string) QueryExecutor {
+	q = q.clone()
+
 	if len(groupBy) == 0 {
 		q.errors = append(q.errors, fmt.Errorf("empty group clauses not allowed"))
 	}
@@ -148,7 +299,27 @@ func (q *query) GroupBy(groupBy []string) QueryExecutor {
 	return q
 }
 
+// group by expressions, e.g. GroupByExpr(JSONText("metadata", "plan")),
+// rendered after the GroupBy columns
+func (q *query) GroupByExpr(exprs ...Expr) QueryExecutor {
+	q = q.clone()
+
+	if len(exprs) == 0 {
+		q.errors = append(q.errors, fmt.Errorf("empty group clauses not allowed"))
+	}
+
+	q.groupExprs = append(q.groupExprs, exprs...)
+	return q
+}
+
+// check if the query groups its rows
+func (q *query) isGrouped() bool {
+	return len(q.groupBy) > 0 || len(q.groupExprs) > 0 || len(q.having) > 0
+}
+
 func (q *query) OrderBy(orderBy []OrderClause) QueryExecutor {
+	q = q.clone()
+
 	if len(orderBy) == 0 {
 		q.errors = append(q.errors, fmt.Errorf("empty orderby clauses not allowed"))
 	}
@@ -158,6 +329,8 @@ func (q *query) OrderBy(orderBy []OrderClause) QueryExecutor {
 }
 
 func (q *query) Having(having []Condition) QueryExecutor {
+	q = q.clone()
+
 	if len(having) == 0 {
 		q.errors = append(q.errors, fmt.Errorf("empty having clauses not allowed"))
 	}
@@ -166,43 +339,111 @@ func (q *query) Having(having []Condition) QueryExecutor {
 	return q
 }
 
+// limit the number of rows, Limit(0) returns no rows
 func (q *query) Limit(limit int) QueryExecutor {
-	q.limit = limit
+	q = q.clone()
+
+	if limit < 0 {
+		q.errors = append(q.errors, fmt.Errorf("invalid limit %d", limit))
+	}
+
+	q.limit = &limit
+	q.withTies = false
+	return q
+}
+
+// limit the number of rows, also returning the rows tied with the last one
+// in the order by, e.g. FETCH FIRST 3 ROWS WITH TIES
+func (q *query) LimitWithTies(limit int) QueryExecutor {
+	q = q.Limit(limit).(*query)
+	q.withTies = true
+	return q
+}
+
+// remove the limit
+func (q *query) NoLimit() QueryExecutor {
+	q = q.clone()
+	q.limit = nil
+	q.withTies = false
 	return q
 }
 
 func (q *query) Offset(offset int) QueryExecutor {
-	q.offset = offset
+	q = q.clone()
+
+	if offset < 0 {
+		q.errors = append(q.errors, fmt.Errorf("invalid offset %d", offset))
+	}
+
+	q.offset = &offset
 	return q
 }
 
-func (q *query) addJoins() error {
-	if len(q.whereConds) > 0 {
-		for _, join := range q.joins {
-			if join.JoinType == "" || join.Table == "" {
-				nerr := fmt.Errorf("join missing type/table")
-				q.errors = append(q.errors, nerr)
-				return nerr
-			}
-			condition := join.Condition
-			q.ArgCount++
+// remove the offset
+func (q *query) NoOffset() QueryExecutor {
+	q = q.clone()
+	q.offset = nil
+	return q
+}
 
-			if condition.Field == "" || condition.Operator == "" || condition.Values[0] == nil {
-				nerr := fmt.Errorf("join condition invalid")
-				q.errors = append(q.errors, nerr)
-				return nerr
-			}
+// bind limit and offset as placeholders so every page shares one statement
+func (q *query) addLimit() error {
+	if q.withTies && len(q.orderBy) == 0 {
+		err := fmt.Errorf("limit with ties requires order by clauses")
+		q.errors = append(q.errors, err)
+		return err
+	}
+
+	if q.limit != nil && !q.withTies {
+		limit, err := q.bindValue(*q.limit)
+		if err != nil {
+			return err
+		}
+		q.queryString += " LIMIT " + limit
+	}
+
+	if q.offset != nil {
+		offset, err := q.bindValue(*q.offset)
+		if err != nil {
+			return err
+		}
 
-			q.QueryString += fmt.Sprintf(" %s %s ON %s %s $%s", join.JoinType, join.Table, condition.Field, condition.Operator, strconv.Itoa(q.ArgCount))
-			q.Args = append(q.Args, condition.Values[0])
+		q.queryString += " OFFSET " + offset
+		if q.withTies {
+			q.queryString += " ROWS"
 		}
 	}
+
+	if q.withTies {
+		limit, err := q.bindValue(*q.limit)
+		if err != nil {
+			return err
+		}
+		q.queryString += fmt.Sprintf(" FETCH FIRST %s ROWS WITH TIES", limit)
+	}
 	return nil
 }
 
 func (q *query) addGroupBy() error {
-	if len(q.groupBy) > 0 {
-		q.QueryString += " GROUP BY " + strings.Join(q.groupBy, ", ")
+	if len(q.groupBy) > 0 || len(q.groupExprs) > 0 {
+		groupCols := make([]string, 0, len(q.groupBy)+len(q.groupExprs))
+		for _, col := range q.groupBy {
+			field, err := q.fieldIdent(col)
+			if err != nil {
+				q.errors = append(q.errors, err)
+				return err
+			}
+			groupCols = append(groupCols, field)
+		}
+		for _, expr := range q.groupExprs {
+			field, err := expr.buildExpr(q)
+			if err != nil {
+				q.errors = append(q.errors, err)
+				return err
+			}
+			groupCols = append(groupCols, field)
+		}
+		q.queryString += " GROUP BY " + strings.Join(groupCols, ", ")
 	}
 	return nil
 }
@@ -210,20 +451,14 @@ func (q *query) addGroupBy() error {
 func (q *query) addHaving() error {
 	if len(q.having) > 0 {
 		var havingClauses []string
-		for _, cond := range q.having {
-			q.ArgCount++
-
-			if cond.Field == "" || cond.Operator == "" || cond.Values[0] == nil {
-				nerr := fmt.Errorf("having condition invalid")
-				q.errors = append(q.errors, nerr)
-				return nerr
-			}
-
-			havingClauses = append(havingClauses, fmt.Sprintf("%s %s $%s", cond.Field, cond.Operator, strconv.Itoa(q.ArgCount)))
-			q.Args = append(q.Args, cond.Values[0])
+		havingClauses, err := q.buildWhereClauses(andConditions(q.having), havingClauses)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return err
 		}
-		q.QueryString += " HAVING "
-		q.QueryString += strings.Join(havingClauses, " AND ")
+
+		q.queryString += " HAVING "
+		q.queryString += strings.Join(havingClauses, " ")
 	}
 	return nil
 }
@@ -232,16 +467,15 @@ func (q *query) addOrderby() error {
 	if len(q.orderBy) > 0 {
 		var orderClauses []string
 		for _, order := range q.orderBy {
-
-			if order.Field == "" || order.Order == "" {
-				nerr := fmt.Errorf("order condition invalid")
-				q.errors = append(q.errors, nerr)
-				return nerr
+			term, err := q.orderTerm(order)
+			if err != nil {
+				q.errors = append(q.errors, err)
+				return err
 			}
 
-			orderClauses = append(orderClauses, fmt.Sprintf("%s %s", order.Field, order.Order))
+			orderClauses = append(orderClauses, term)
 		}
-		q.QueryString += " ORDER BY " + strings.Join(orderClauses, ", ")
+		q.queryString += " ORDER BY " + strings.Join(orderClauses, ", ")
 	}
 	return nil
 }
@@ -260,13 +494,59 @@ func (q *query) checkPreBuildErrors() error {
 }
 
 func (q *query) BuildSelectQuery() error {
+	err := q.buildSelect()
+	if err != nil {
+		return err
+	}
+
+	q.queryString += ";"
+
+	return nil
+}
+
+// builds the select statement without the terminating semicolon
+func (q *query) buildSelect() error {
 	err := q.checkPreBuildErrors()
 	if err != nil {
 		return err
 	}
 
+	// common tables come first so their placeholders are numbered first
+	err = q.addWith()
+	if err != nil {
+		return err
+	}
+	start := len(q.queryString)
+
+	table, err := q.tableIdent(q.table)
+	if err != nil {
+		q.errors = append(q.errors, err)
+		return err
+	}
+
+	selects := q.selects
+	if len(selects) == 0 {
+		selects = []Expr{selectColumn("*")}
+	}
+
+	q.aliases = selectAliases(selects)
+	selectCols := make([]string, len(selects))
+	for i, col := range selects {
+		selectCols[i], err = col.buildExpr(q)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return err
+		}
+	}
+
 	// Start building the query
-	q.QueryString = fmt.Sprintf("SELECT %s FROM %s", strings.Join(q.cols, ", "), q.table)
+	distinct, err := q.buildDistinct()
+	if err != nil {
+		q.errors = append(q.errors, err)
+		return err
+	}
+
+	q.queryString += fmt.Sprintf("SELECT %s%s FROM %s", distinct, strings.Join(selectCols, ", "), table)
 
 	// Add JOIN clauses
 	err = q.addJoins()
@@ -292,6 +572,18 @@ func (q *query) BuildSelectQuery() error {
 		return err
 	}
 
+	// Add WINDOW definitions
+	err = q.addWindows()
+	if err != nil {
+		return err
+	}
+
+	// Add UNION / INTERSECT / EXCEPT, order and limits apply to the combined rows
+	err = q.addSetOperations(start)
+	if err != nil {
+		return err
+	}
+
 	// Add ORDER BY
 	err = q.addOrderby()
 	if err != nil {
@@ -299,19 +591,22 @@ func (q *query) BuildSelectQuery() error {
 	}
 
 	// Add LIMIT and OFFSET
-	if q.limit > 0 {
-		q.QueryString += fmt.Sprintf(" LIMIT %d", q.limit)
-	}
-	if q.offset > 0 {
-		q.QueryString += fmt.Sprintf(" OFFSET %d", q.offset)
+	err = q.addLimit()
+	if err != nil {
+		return err
 	}
 
-	q.QueryString += ";"
+	// Add FOR UPDATE / FOR SHARE
+	err = q.addLocks()
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
 
 func (q *query) FindOne(id string) ([]any, error) {
+	q = q.clone()
 	q.whereConds = []Condition{
 		{Field: "_id", Operator: "=", Values: []any{id}},
 	}
@@ -331,46 +626,41 @@ func (q *query) FindOne(id string) ([]any, error) {
 
 // implements the select functionality
 func (q *query) Find() ([][]any, error) {
-	err := q.BuildSelectQuery()
+	db, queryString, args, err := q.prepareSelect()
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println("queryString ", q.QueryString, q.Args)
-	rows, err := q.conn.GetDB().Query(q.QueryString, q.Args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	return queryRows(q.context(), db, queryString, args)
+}
 
-	var rowData [][]any
+// build the select statement on a fresh copy and resolve its arguments
+func (q *query) prepareSelect() (runner, string, []any, error) {
+	q = q.fresh()
 
-	cols, err := rows.Columns()
+	err := q.BuildSelectQuery()
 	if err != nil {
-		return nil, err
+		return nil, "", nil, err
 	}
 
-	for rows.Next() {
-
-		vals := make([]any, len(cols))
-		scanArgs := make([]any, len(cols))
-
-		for i := range vals {
-			scanArgs[i] = &vals[i]
-		}
+	fmt.Println("queryString ", q.queryString, q.args)
 
-		if err := rows.Scan(scanArgs...); err != nil {
-			return nil, err
-		}
+	err = q.checkLockTx()
+	if err != nil {
+		return nil, "", nil, err
+	}
 
-		rowData = append(rowData, vals)
+	db, err := q.runner()
+	if err != nil {
+		return nil, "", nil, err
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	args, err := bindParams(q.args, nil)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
-	return rowData, err
+	return db, q.queryString, args, nil
 }
 
 // constructor for query which adds handle for the db connection
@@ -387,8 +677,11 @@ func (q *query) init(conn ConnectionExecutor) error {
 }
 
 // contructor for query
-func NewQuery(conn ConnectionExecutor) (QueryExecutor, error) {
+func NewQuery(conn ConnectionExecutor, opts ...QueryOption) (QueryExecutor, error) {
 	q := &query{}
+	for _, opt := range opts {
+		opt(q)
+	}
 
 	err := q.init(conn)
 	if err != nil {
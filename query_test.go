@@ -244,8 +244,8 @@ func TestBasicQueries(t *testing.T) {
 	// // SELECT _id, title FROM people WHERE shift_tmtz_start > '18:55:23+05:30' AND shift_tmtz_end < '10:35:23+05:30'
 	// //  ORDER BY fire_team ASC
 	// //  LIMIT 10 OFFSET 5;
-	queryString7 := `SELECT _id, title FROM people WHERE  shift_tmtz_start > $1 AND  shift_tmtz_end < $2 ORDER BY fire_team ASC LIMIT 10 OFFSET 5;`
-	queryArgs7 := []driver.Value{`18:55:23+05:30`, `10:35:23+05:30`}
+	queryString7 := `SELECT _id, title FROM people WHERE  shift_tmtz_start > $1 AND  shift_tmtz_end < $2 ORDER BY fire_team ASC LIMIT $3 OFFSET $4;`
+	queryArgs7 := []driver.Value{`18:55:23+05:30`, `10:35:23+05:30`, 10, 5}
 	query7, err := NewQuery(conn)
 	assert.Nil(t, err)
 
@@ -289,8 +289,8 @@ func TestBasicQueries(t *testing.T) {
 	//  ORDER BY fire_team DESC LIMIT 10;
 	queryString9 := `SELECT _id, email FROM people WHERE  shift_tmtz_start > $1 AND  shift_tmtz_end < $2 OR
 	 (  location = $3 AND  fire_team = $4 ) OR (  email = $5 AND  slack_handle = $6 )
-	    AND ( fire_team  IN ($7, $8) AND _id  BETWEEN $9 AND $10 ) ORDER BY fire_team DESC LIMIT 10;`
-	queryArgs9 := []driver.Value{`18:55:23+05:30`, `10:35:23+05:30`, `FR`, `fortrot`, `mdykasm1@naver.com`, `mdykasm1`, `echo`, `foxtrot`, `1`, `500`}
+	    AND ( fire_team  IN ($7, $8) AND _id  BETWEEN $9 AND $10 ) ORDER BY fire_team DESC LIMIT $11 OFFSET $12;`
+	queryArgs9 := []driver.Value{`18:55:23+05:30`, `10:35:23+05:30`, `FR`, `fortrot`, `mdykasm1@naver.com`, `mdykasm1`, `echo`, `foxtrot`, `1`, `500`, 10, 0}
 	query9, err := NewQuery(conn)
 	assert.Nil(t, err)
 
@@ -372,8 +372,8 @@ func TestAdvanceQueries(t *testing.T) {
 	//  GROUP BY _id, location, fire_team
 	//  ORDER BY shift_tmtz_start ASC
 	//  LIMIT 10;
-	queryString2 := `SELECT _id, email FROM people WHERE  shift_tmtz_start > $1 AND  shift_tmtz_end < $2 OR (  location = $3 AND  fire_team = $4 ) OR (  email = $5 AND  slack_handle = $6 ) GROUP BY _id, location, fire_team ORDER BY shift_tmtz_start ASC LIMIT 10;`
-	queryArgs2 := []driver.Value{`18:55:23+05:30`, `10:35:23+05:30`, `FR`, `fortrot`, `mdykasm1@naver.com`, `mdykasm1`}
+	queryString2 := `SELECT _id, email FROM people WHERE  shift_tmtz_start > $1 AND  shift_tmtz_end < $2 OR (  location = $3 AND  fire_team = $4 ) OR (  email = $5 AND  slack_handle = $6 ) GROUP BY _id, location, fire_team ORDER BY shift_tmtz_start ASC LIMIT $7 OFFSET $8;`
+	queryArgs2 := []driver.Value{`18:55:23+05:30`, `10:35:23+05:30`, `FR`, `fortrot`, `mdykasm1@naver.com`, `mdykasm1`, 10, 0}
 	query2, err := NewQuery(conn)
 	assert.Nil(t, err)
 
@@ -414,8 +414,8 @@ func TestAdvanceQueries(t *testing.T) {
 	//  HAVING location = 'FR' AND shift_type = 'nocturnal'
 	//  ORDER BY shift_tmtz_start ASC
 	//  LIMIT 10;
-	queryString3 := `SELECT * FROM people WHERE  shift_tmtz_start > $1 AND  shift_tmtz_end < $2 OR (  location = $3 AND  fire_team = $4 ) OR (  email = $5 AND  slack_handle = $6 ) GROUP BY _id, location, fire_team HAVING location = $7 AND shift_type = $8 ORDER BY shift_tmtz_start ASC LIMIT 10;`
-	queryArgs3 := []driver.Value{`18:55:23+05:30`, `10:35:23+05:30`, `FR`, `fortrot`, `mdykasm1@naver.com`, `mdykasm1`, `FR`, `nocturnal`}
+	queryString3 := `SELECT * FROM people WHERE  shift_tmtz_start > $1 AND  shift_tmtz_end < $2 OR (  location = $3 AND  fire_team = $4 ) OR (  email = $5 AND  slack_handle = $6 ) GROUP BY _id, location, fire_team HAVING  location = $7 AND  shift_type = $8 ORDER BY shift_tmtz_start ASC LIMIT $9 OFFSET $10;`
+	queryArgs3 := []driver.Value{`18:55:23+05:30`, `10:35:23+05:30`, `FR`, `fortrot`, `mdykasm1@naver.com`, `mdykasm1`, `FR`, `nocturnal`, 10, 0}
 	query3, err := NewQuery(conn)
 	assert.Nil(t, err)
 
@@ -451,3 +451,55 @@ func TestAdvanceQueries(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, rows)
 }
+
+// join and where conditions should be able to compare columns
+func TestColumnComparisons(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	// SELECT * FROM members JOIN teams ON members.team_id = teams.id AND teams.active = true WHERE members.joined_at > teams.created_at;
+	queryString1 := `SELECT * FROM members JOIN teams ON members.team_id = teams.id AND  teams.active = $1 WHERE  members.joined_at > teams.created_at;`
+	queryArgs1 := []driver.Value{true}
+	query1, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(queryString1).WithArgs(queryArgs1...).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+	rows, err := query1.Select().For("members").Join([]JoinClause{
+		{JoinType: BasicJoin, Table: "teams", Conditions: []Condition{
+			{Field: "members.team_id", Operator: "=", Values: []any{Col("teams.id")}},
+			{Field: "teams.active", Operator: "=", Values: []any{true}},
+		}},
+	}).Where([]Condition{
+		{Field: "members.joined_at", Operator: ">", Values: []any{Col("teams.created_at")}},
+	}).Find()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+
+	// SELECT * FROM members LEFT JOIN teams ON members.team_id = teams.id OR members.lead_id = teams.lead_id WHERE members._id BETWEEN teams.min_id AND $1;
+	queryString2 := `SELECT * FROM members LEFT JOIN teams ON members.team_id = teams.id OR  members.lead_id = teams.lead_id WHERE members._id  BETWEEN teams.min_id AND $1;`
+	queryArgs2 := []driver.Value{`500`}
+	query2, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(queryString2).WithArgs(queryArgs2...).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+	rows, err = query2.Select().For("members").Join([]JoinClause{
+		{JoinType: LeftJoin, Table: "teams", Conditions: []Condition{
+			{Field: "members.team_id", Operator: "=", Values: []any{Col("teams.id")}, NextLogicalOp: "OR"},
+			{Field: "members.lead_id", Operator: "=", Values: []any{Col("teams.lead_id")}},
+		}},
+	}).Where([]Condition{
+		{Field: "members._id", Type: ConditionBetween, Values: []any{Col("teams.min_id"), "500"}},
+	}).Find()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+}
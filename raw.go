@@ -0,0 +1,296 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RawExpr is a sql fragment with its arguments, for what the builder cannot
+// express. Its placeholders are renumbered into the query, either ? (?? for a
+// literal question mark) or $1-style numbered from the fragment's own
+// arguments, in which case ? is left untouched. Literals, quoted identifiers,
+// dollar quoted strings and comments are left as is. The sql is not
+// validated, never build it from user input.
+type RawExpr struct {
+	sql  string
+	args []any
+}
+
+// raw sql fragment usable as a select column, order expression, join source
+// or, with RawCondition, a condition
+//
+//	Select("_id").SelectExpr(Raw("age(now(), born_at)").As("age"))
+//	OrderBy([]OrderClause{{Expr: Raw("score * ?", weight), Order: Desc}})
+func Raw(sql string, args ...any) RawExpr {
+	return RawExpr{sql: sql, args: args}
+}
+
+// raw sql predicate, e.g. RawCondition("created_at > now() - ?::interval", "7 days")
+func RawCondition(sql string, args ...any) Condition {
+	return Condition{Type: ConditionRaw, Expr: Raw(sql, args...)}
+}
+
+// name the result column
+func (r RawExpr) As(alias string) Expr {
+	return Alias(r, alias)
+}
+
+// find the end of the $n placeholder starting at i, or -1
+func dollarPlaceholder(s string, i int) int {
+	if s[i] != '$' || i+1 >= len(s) || s[i+1] < '0' || s[i+1] > '9' {
+		return -1
+	}
+
+	// part of an identifier such as col$1
+	if i > 0 && isIdentByte(s[i-1]) {
+		return -1
+	}
+
+	j := i + 1
+	for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+		j++
+	}
+	return j
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// opening tag of a dollar quoted string, e.g. $$ or $body$
+var dollarQuoteRe = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// end of the literal, quoted identifier or comment starting at i, i when
+// none starts there
+func skipQuoted(s string, i int) (int, error) {
+	switch {
+	case s[i] == '\'':
+		// E'...' strings escape with a backslash, '' is a quote in any string
+		escapes := i > 0 && (s[i-1] == 'E' || s[i-1] == 'e') && (i == 1 || !isIdentByte(s[i-2]))
+		for j := i + 1; j < len(s); j++ {
+			switch {
+			case escapes && s[j] == '\\':
+				j++
+			case s[j] == '\'' && j+1 < len(s) && s[j+1] == '\'':
+				j++
+			case s[j] == '\'':
+				return j + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("raw sql has an unterminated quote")
+	case s[i] == '"':
+		end := strings.IndexByte(s[i+1:], '"')
+		if end == -1 {
+			return 0, fmt.Errorf("raw sql has an unterminated quote")
+		}
+		return i + end + 2, nil
+	case strings.HasPrefix(s[i:], "--"):
+		// the fragment is embedded, an open comment would swallow the rest of the query
+		end := strings.IndexByte(s[i:], '\n')
+		if end == -1 {
+			return 0, fmt.Errorf("raw sql line comment must end with a newline")
+		}
+		return i + end + 1, nil
+	case strings.HasPrefix(s[i:], "/*"):
+		// block comments nest
+		depth := 0
+		for j := i; j+1 < len(s); j++ {
+			switch s[j : j+2] {
+			case "/*":
+				depth++
+				j++
+			case "*/":
+				depth--
+				j++
+				if depth == 0 {
+					return j + 1, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("raw sql has an unterminated comment")
+	case s[i] == '$' && (i == 0 || !isIdentByte(s[i-1])):
+		tag := dollarQuoteRe.FindString(s[i:])
+		if tag == "" {
+			return i, nil
+		}
+
+		end := strings.Index(s[i+len(tag):], tag)
+		if end == -1 {
+			return 0, fmt.Errorf("raw sql has an unterminated dollar quote")
+		}
+		return i + len(tag) + end + len(tag), nil
+	}
+	return i, nil
+}
+
+// walk the fragment outside of literals, quoted identifiers and comments, fn
+// returns how many bytes it consumed at i, 0 to copy the byte as is
+func scanRaw(s string, fn func(i int) (int, error)) error {
+	for i := 0; i < len(s); {
+		end, err := skipQuoted(s, i)
+		if err != nil {
+			return err
+		}
+		if end > i {
+			i = end
+			continue
+		}
+
+		n, err := fn(i)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			n = 1
+		}
+		i += n
+	}
+	return nil
+}
+
+// bind the arguments of the fragment, renumbering its placeholders
+func (r RawExpr) buildExpr(q *query) (string, error) {
+	if strings.TrimSpace(r.sql) == "" {
+		return "", fmt.Errorf("empty raw sql not allowed")
+	}
+
+	// numbered placeholders take precedence, ? is then an operator
+	dollar := false
+	err := scanRaw(r.sql, func(i int) (int, error) {
+		if dollarPlaceholder(r.sql, i) != -1 {
+			dollar = true
+		}
+		return 0, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	last := 0
+	used := 0
+	bound := make(map[int]string)
+
+	err = scanRaw(r.sql, func(i int) (int, error) {
+		if dollar {
+			end := dollarPlaceholder(r.sql, i)
+			if end == -1 {
+				return 0, nil
+			}
+
+			n, _ := strconv.Atoi(r.sql[i+1 : end])
+			if n < 1 || n > len(r.args) {
+				return 0, fmt.Errorf("raw sql placeholder $%d has no argument", n)
+			}
+
+			ph, ok := bound[n]
+			if !ok {
+				var err error
+				ph, err = q.bindValue(r.args[n-1])
+				if err != nil {
+					return 0, err
+				}
+				bound[n] = ph
+			}
+
+			sb.WriteString(r.sql[last:i])
+			sb.WriteString(ph)
+			last = end
+			return end - i, nil
+		}
+
+		if r.sql[i] != '?' {
+			return 0, nil
+		}
+
+		// ?? is a literal question mark, e.g. the jsonb key exists operator
+		if i+1 < len(r.sql) && r.sql[i+1] == '?' {
+			sb.WriteString(r.sql[last : i+1])
+			last = i + 2
+			return 2, nil
+		}
+
+		if used >= len(r.args) {
+			return 0, fmt.Errorf("raw sql has more placeholders than arguments")
+		}
+
+		ph, err := q.bindValue(r.args[used])
+		if err != nil {
+			return 0, err
+		}
+		used++
+
+		sb.WriteString(r.sql[last:i])
+		sb.WriteString(ph)
+		last = i + 1
+		return 1, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(r.sql[last:])
+
+	if (dollar && len(bound) != len(r.args)) || (!dollar && used != len(r.args)) {
+		return "", fmt.Errorf("raw sql has unused arguments")
+	}
+
+	return sb.String(), nil
+}
+
+// render a standalone raw statement, placeholders are numbered from 1
+func (q *query) buildRaw(sqlStr string, args []any) error {
+	err := q.checkPreBuildErrors()
+	if err != nil {
+		return err
+	}
+
+	c := q.fresh()
+	sqlStr, err = Raw(sqlStr, args...).buildExpr(c)
+	if err != nil {
+		return err
+	}
+
+	q.queryString = strings.TrimSuffix(strings.TrimSpace(sqlStr), ";") + ";"
+	q.args = c.args
+	q.argCount = c.argCount
+
+	return nil
+}
+
+// run a raw select statement, in the transaction and context of the query,
+// returning rows like Find
+func (q *query) QueryRaw(sqlStr string, args ...any) ([][]any, error) {
+	q = q.fresh()
+
+	err := q.buildRaw(sqlStr, args)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := q.runner()
+	if err != nil {
+		return nil, err
+	}
+
+	bound, err := bindParams(q.args, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return queryRows(q.context(), db, q.queryString, bound)
+}
+
+// run a raw statement, in the transaction and context of the query,
+// returning the number of affected rows like Update and Delete
+func (q *query) Exec(sqlStr string, args ...any) (int64, error) {
+	q = q.fresh()
+
+	err := q.buildRaw(sqlStr, args)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.exec()
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRawFragments(t *testing.T) {
+	// ? placeholders continue the numbering of the outer query
+	sqlStr, args, err := NewBuilder().Select("_id").SelectExpr(
+		Raw("date_trunc(?, created_at)", "day").As("day"),
+	).For("audit").Join([]JoinClause{
+		{JoinType: CrossJoin, Source: Raw("generate_series(1, ?)", 3), Alias: "n"},
+	}).Where([]Condition{
+		And(
+			Eq("actor", "jpomfrette"),
+			RawCondition("created_at > now() - ?::interval", "7 days"),
+			Not(RawCondition("payload ?? 'deleted'")),
+		),
+	}).OrderBy([]OrderClause{{Expr: Raw("abs(score - ?)", 5), Order: Asc}}).Limit(10).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id, date_trunc($1, created_at) AS day FROM audit CROSS JOIN generate_series(1, $2) AS n WHERE (  actor = $3 AND (created_at > now() - $4::interval) AND NOT (payload ? 'deleted') ) ORDER BY abs(score - $5) ASC LIMIT $6;`, sqlStr)
+	assert.Equal(t, []any{"day", 3, "jpomfrette", "7 days", 5, 10}, args)
+
+	// $n placeholders are bound once, ? and quoted text are left as is
+	sqlStr, args, err = NewBuilder().Select("_id").For("audit").Where([]Condition{
+		And(
+			Eq("actor", "jpomfrette"),
+			RawCondition(`payload ? 'key' AND (ip = $2 OR $2 IS NULL) AND note <> '$1' AND at > $1`, "2024-03-01", nil),
+		),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM audit WHERE (  actor = $1 AND (payload ? 'key' AND (ip = $2 OR $2 IS NULL) AND note <> '$1' AND at > $3) );`, sqlStr)
+	assert.Equal(t, []any{"jpomfrette", nil, "2024-03-01"}, args)
+
+	// escape strings, dollar quoted bodies and comments are left as is
+	sqlStr, args, err = NewBuilder().Select("_id").For("audit").Where([]Condition{
+		RawCondition("note <> E'it\\'s ?' AND format($fmt$%s ? $1$fmt$, actor) <> ? -- why not ?\nAND /* $1 /* ? */ ? */ at < ?", "x", "2024-03-01"),
+	}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT _id FROM audit WHERE (note <> E'it\\'s ?' AND format($fmt$%s ? $1$fmt$, actor) <> $1 -- why not ?\nAND /* $1 /* ? */ ? */ at < $2);", sqlStr)
+	assert.Equal(t, []any{"x", "2024-03-01"}, args)
+
+	// failure cases
+	invalid := []RawExpr{
+		Raw(""),
+		Raw("score > ?"),
+		Raw("score > ?", 1, 2),
+		Raw("score > $2", 1),
+		Raw("score > $1", 1, 2),
+		Raw("note = 'open", 1),
+		Raw("note = E'open\\'", 1),
+		Raw("note = $$open ?", 1),
+		Raw("note = ? /* open", 1),
+		Raw("note = ? -- open", 1),
+	}
+
+	for _, raw := range invalid {
+		_, _, err = NewBuilder().Select("_id").For("audit").Where([]Condition{{Type: ConditionRaw, Expr: raw}}).ToSQL()
+		assert.NotNil(t, err, raw.sql)
+	}
+
+	// a raw join source needs an alias
+	_, _, err = NewBuilder().Select("_id").For("audit").Join([]JoinClause{
+		{JoinType: CrossJoin, Source: Raw("generate_series(1, 3)")},
+	}).ToSQL()
+	assert.NotNil(t, err)
+}
+
+func TestRawStatements(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(`SELECT actor, count(*) FROM audit WHERE at > $1 GROUP BY actor;`).WithArgs(`2024-03-01`).
+		WillReturnRows(sqlmock.NewRows([]string{"actor", "count"}).AddRow("jpomfrette", int64(3)))
+
+	rows, err := q.QueryRaw("SELECT actor, count(*) FROM audit WHERE at > ? GROUP BY actor", "2024-03-01")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]any{{"jpomfrette", int64(3)}}, rows)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE audit SET archived = true WHERE at < $1 AND actor <> $2;`).WithArgs(`2024-01-01`, `system`).WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+
+	tx, err := conn.GetDB().Begin()
+	assert.Nil(t, err)
+
+	count, err := q.InTx(tx).Exec("UPDATE audit SET archived = true WHERE at < $1 AND actor <> $2;", "2024-01-01", "system")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), count)
+
+	assert.Nil(t, tx.Commit())
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	_, err = q.Exec("DELETE FROM audit WHERE actor = ?")
+	assert.NotNil(t, err)
+}
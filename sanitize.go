@@ -0,0 +1,278 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Schema registers tables and their columns, a query built with WithSchema
+// rejects any table or column that is not registered here
+type Schema map[string][]string
+
+// QueryOption configures a query at construction
+type QueryOption func(*query)
+
+// quote every identifier, e.g. "public"."people"."_id"
+func WithQuotedIdentifiers() QueryOption {
+	return func(q *query) {
+		q.quoteIdents = true
+	}
+}
+
+// strict mode, only identifiers registered in the schema are allowed
+func WithSchema(schema Schema) QueryOption {
+	return func(q *query) {
+		q.schema = schema
+	}
+}
+
+const identPattern = `[A-Za-z_][A-Za-z0-9_$]*`
+
+var (
+	// schema.table.col, table.col, col or table.*
+	identRe = regexp.MustCompile(`^` + identPattern + `(\.` + identPattern + `){0,2}(\.\*)?$`)
+
+	// aggregate call over a column, e.g. COUNT(*) or COUNT(DISTINCT people._id)
+	aggregateRe = regexp.MustCompile(`^(` + identPattern + `)\(\s*(\*|(?i:DISTINCT\s+)?` + identPattern + `(?:\.` + identPattern + `){0,2})\s*\)$`)
+
+	// collation name, always rendered quoted
+	collationRe = regexp.MustCompile(`^[A-Za-z0-9_.@-]+$`)
+
+	// trailing alias of a select column
+	aliasRe = regexp.MustCompile(`^(.+?)\s+(?i:AS)\s+(` + identPattern + `)$`)
+)
+
+// aggregate functions allowed in fields
+var aggregateFuncs = map[string]bool{
+	"count":     true,
+	"sum":       true,
+	"avg":       true,
+	"min":       true,
+	"max":       true,
+	"array_agg": true,
+	"bool_and":  true,
+	"bool_or":   true,
+	"every":     true,
+	"json_agg":  true,
+	"jsonb_agg": true,
+}
+
+// comparison operators allowed in conditions
+var validOperators = map[string]bool{
+	"=":                    true,
+	"<>":                   true,
+	"!=":                   true,
+	"<":                    true,
+	">":                    true,
+	"<=":                   true,
+	">=":                   true,
+	"LIKE":                 true,
+	"NOT LIKE":             true,
+	"ILIKE":                true,
+	"NOT ILIKE":            true,
+	"SIMILAR TO":           true,
+	"NOT SIMILAR TO":       true,
+	"IS DISTINCT FROM":     true,
+	"IS NOT DISTINCT FROM": true,
+}
+
+// validate a condition operator against the allow-list
+func normalizeOperator(op string) (string, error) {
+	norm := strings.ToUpper(strings.Join(strings.Fields(op), " "))
+	if !validOperators[norm] {
+		return "", fmt.Errorf("invalid operator %q", op)
+	}
+	return norm, nil
+}
+
+// validate the logical operator between conditions
+func normalizeLogicalOp(op string) (string, error) {
+	norm := strings.ToUpper(strings.TrimSpace(op))
+	if norm != "AND" && norm != "OR" {
+		return "", fmt.Errorf("invalid logical operator %q", op)
+	}
+	return norm, nil
+}
+
+// validate a sort direction
+func normalizeDirection(order SortDirection) (SortDirection, error) {
+	norm := SortDirection(strings.ToUpper(strings.TrimSpace(string(order))))
+	if norm != Asc && norm != Desc {
+		return "", fmt.Errorf("invalid sort direction %q", order)
+	}
+	return norm, nil
+}
+
+// quote a single identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// tables and aliases visible to the query, mapped to their schema table
+func (q *query) scope() map[string]string {
+	scope := make(map[string]string, len(q.outerScope)+len(q.joins)+1)
+	for k, v := range q.outerScope {
+		scope[k] = v
+	}
+
+	if q.table != "" {
+		scope[q.table] = q.table
+	}
+
+	// columns of common tables are checked by their own queries
+	for _, cte := range q.ctes {
+		scope[cte.name] = ""
+	}
+
+	for _, join := range q.joins {
+		switch {
+		case (join.Lateral != nil || join.Source != nil) && join.Alias != "":
+			scope[join.Alias] = ""
+		case join.Alias != "":
+			scope[join.Alias] = join.Table
+		case join.Table != "":
+			scope[join.Table] = join.Table
+		}
+	}
+	return scope
+}
+
+// strict mode check of a column reference against the schema
+func (q *query) checkSchemaColumn(ref string) error {
+	if ref == "*" {
+		return nil
+	}
+
+	scope := q.scope()
+
+	if dot := strings.LastIndex(ref, "."); dot != -1 {
+		prefix, col := ref[:dot], ref[dot+1:]
+		table, ok := scope[prefix]
+		if !ok {
+			return fmt.Errorf("unknown table %q", prefix)
+		}
+
+		// columns of a lateral sub query are checked by the sub query itself
+		if table == "" || col == "*" || slices.Contains(q.schema[table], col) {
+			return nil
+		}
+		return fmt.Errorf("unknown column %q", ref)
+	}
+
+	// columns of common tables and sub queries are unknown, they must be qualified
+	for _, table := range scope {
+		if table != "" && slices.Contains(q.schema[table], ref) {
+			return nil
+		}
+	}
+
+	if slices.Contains(q.aliases, ref) {
+		return nil
+	}
+
+	return fmt.Errorf("unknown column %q", ref)
+}
+
+// render a dotted identifier, quoting each part if enabled
+func (q *query) renderIdent(ref string) string {
+	if !q.quoteIdents || ref == "*" {
+		return ref
+	}
+
+	parts := strings.Split(ref, ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = quoteIdent(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// render a table reference
+func (q *query) tableIdent(name string) (string, error) {
+	if !identRe.MatchString(name) || strings.HasSuffix(name, "*") {
+		return "", fmt.Errorf("invalid table %q", name)
+	}
+
+	if q.schema != nil {
+		_, ok := q.schema[name]
+		if !ok && !q.isCommonTable(name) {
+			return "", fmt.Errorf("unknown table %q", name)
+		}
+	}
+
+	return q.renderIdent(name), nil
+}
+
+// render a bare identifier such as an alias
+func (q *query) aliasIdent(name string) (string, error) {
+	if !identRe.MatchString(name) || strings.Contains(name, ".") {
+		return "", fmt.Errorf("invalid alias %q", name)
+	}
+
+	return q.renderIdent(name), nil
+}
+
+// render a column reference, also accepts aggregates over a column
+func (q *query) fieldIdent(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "*" || identRe.MatchString(name) {
+		if q.schema != nil {
+			err := q.checkSchemaColumn(name)
+			if err != nil {
+				return "", err
+			}
+		}
+		return q.renderIdent(name), nil
+	}
+
+	match := aggregateRe.FindStringSubmatch(name)
+	if match == nil || !aggregateFuncs[strings.ToLower(match[1])] {
+		return "", fmt.Errorf("invalid field %q", name)
+	}
+
+	arg := match[2]
+	distinct := ""
+	if fields := strings.Fields(arg); len(fields) == 2 {
+		distinct, arg = "DISTINCT ", fields[1]
+	}
+
+	col, err := q.fieldIdent(arg)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s(%s%s)", strings.ToUpper(match[1]), distinct, col), nil
+}
+
+// render a select column with an optional alias
+func (q *query) selectIdent(col string) (string, error) {
+	if match := aliasRe.FindStringSubmatch(strings.TrimSpace(col)); match != nil {
+		field, err := q.fieldIdent(match[1])
+		if err != nil {
+			return "", err
+		}
+
+		alias, err := q.aliasIdent(match[2])
+		if err != nil {
+			return "", err
+		}
+		return field + " AS " + alias, nil
+	}
+
+	return q.fieldIdent(col)
+}
+
+// collect the select column aliases, these may be referenced by order by
+func selectAliases(cols []Expr) []string {
+	var aliases []string
+	for _, col := range cols {
+		if alias := exprAlias(col); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
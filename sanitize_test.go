@@ -0,0 +1,172 @@
+package database
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestQuotedIdentifiers(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	// SELECT "_id", COUNT(*) AS "total" FROM "public"."people" JOIN "teams" AS "t" ON "people"."team_id" = "t"."id" WHERE "people"."location" = 'FR' GROUP BY "_id" ORDER BY "_id" DESC;
+	queryString1 := `SELECT "_id", COUNT(*) AS "total" FROM "public"."people" JOIN "teams" AS "t" ON "public"."people"."team_id" = "t"."id" WHERE  "location" = $1 GROUP BY "_id" ORDER BY "_id" DESC;`
+	queryArgs1 := []driver.Value{`FR`}
+	query1, err := NewQuery(conn, WithQuotedIdentifiers())
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(queryString1).WithArgs(queryArgs1...).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+	rows, err := query1.Select("_id", "count(*) as total").For("public.people").Join([]JoinClause{
+		{JoinType: BasicJoin, Table: "teams", Alias: "t", Condition: Eq("public.people.team_id", Col("t.id"))},
+	}).Where([]Condition{
+		Eq("location", "FR"),
+	}).GroupBy([]string{"_id"}).OrderBy([]OrderClause{
+		{Field: "_id", Order: "desc"},
+	}).Find()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+
+	// sub queries are quoted like the outer query
+	sqlStr, _, err := NewBuilder(WithQuotedIdentifiers()).With("recent", NewBuilder().Select("_id").For("orders")).Select("_id").For("recent").ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `WITH "recent" AS (SELECT "_id" FROM "orders") SELECT "_id" FROM "recent";`, sqlStr)
+}
+
+func TestRejectUnsafeInput(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	defer conn.Close()
+
+	unsafe := []struct {
+		build func(q QueryExecutor) QueryExecutor
+		err   string
+	}{
+		{func(q QueryExecutor) QueryExecutor {
+			return q.Select().For("people; DROP TABLE people")
+		}, "invalid table"},
+		{func(q QueryExecutor) QueryExecutor {
+			return q.Select().For("people").Where([]Condition{Eq("1=1 OR email", "x")})
+		}, "invalid field"},
+		{func(q QueryExecutor) QueryExecutor {
+			return q.Select().For("people").Where([]Condition{{Field: "email", Operator: "= '' OR 1=1 --", Values: []any{"x"}}})
+		}, "invalid operator"},
+		{func(q QueryExecutor) QueryExecutor {
+			// IS takes no bound value, null checks use IS NOT DISTINCT FROM
+			return q.Select().For("people").Where([]Condition{{Field: "email", Operator: "IS", Values: []any{nil}}})
+		}, "invalid operator"},
+		{func(q QueryExecutor) QueryExecutor {
+			return q.Select().For("people").Where([]Condition{{Field: "email", Operator: "=", Values: []any{"x"}, NextLogicalOp: "OR 1=1 OR"}, Eq("_id", 1)})
+		}, "invalid logical operator"},
+		{func(q QueryExecutor) QueryExecutor {
+			return q.Select().For("people").OrderBy([]OrderClause{{Field: "email", Order: "ASC, (SELECT 1)"}})
+		}, "invalid sort direction"},
+		{func(q QueryExecutor) QueryExecutor {
+			return q.Select().For("people").GroupBy([]string{"pg_sleep(10)"})
+		}, "invalid field"},
+		{func(q QueryExecutor) QueryExecutor {
+			return q.Select("email AS x; --").For("people")
+		}, "invalid field"},
+	}
+
+	for _, tc := range unsafe {
+		q, err := NewQuery(conn)
+		assert.Nil(t, err)
+
+		_, err = tc.build(q).Find()
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), tc.err)
+	}
+}
+
+func TestStrictSchema(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	schema := Schema{
+		"people": {"_id", "email", "team_id"},
+		"teams":  {"id", "name"},
+	}
+
+	queryString1 := `SELECT email, t.name FROM people JOIN teams AS t ON people.team_id = t.id WHERE  email = $1 ORDER BY email ASC;`
+	queryArgs1 := []driver.Value{`x@y.z`}
+	query1, err := NewQuery(conn, WithSchema(schema))
+	assert.Nil(t, err)
+
+	mock.ExpectQuery(queryString1).WithArgs(queryArgs1...).WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow([]driver.Value{"x@y.z"}...))
+
+	rows, err := query1.Select("email", "t.name").For("people").Join([]JoinClause{
+		{JoinType: BasicJoin, Table: "teams", Alias: "t", Condition: Eq("people.team_id", Col("t.id"))},
+	}).Where([]Condition{Eq("email", "x@y.z")}).OrderBy([]OrderClause{{Field: "email", Order: "ASC"}}).Find()
+
+	assert.Nil(t, err)
+	assert.NotNil(t, rows)
+
+	// unregistered table
+	query2, err := NewQuery(conn, WithSchema(schema))
+	assert.Nil(t, err)
+
+	_, err = query2.Select().For("secrets").Find()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unknown table "secrets"`)
+
+	// unregistered column
+	query3, err := NewQuery(conn, WithSchema(schema))
+	assert.Nil(t, err)
+
+	_, err = query3.Select().For("people").OrderBy([]OrderClause{{Field: "password", Order: "ASC"}}).Find()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unknown column "password"`)
+
+	// unregistered column of a joined table
+	query4, err := NewQuery(conn, WithSchema(schema))
+	assert.Nil(t, err)
+
+	_, err = query4.Select().For("people").Join([]JoinClause{
+		{JoinType: BasicJoin, Table: "teams", Condition: Eq("people.team_id", Col("teams.owner_id"))},
+	}).Find()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unknown column "teams.owner_id"`)
+
+	// sub queries are checked against the schema of the outer query
+	_, _, err = NewBuilder(WithSchema(schema)).With("leak", NewBuilder().Select().For("secrets")).Select().For("leak").ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unknown table "secrets"`)
+
+	_, _, err = NewBuilder(WithSchema(schema)).Select("email").For("people").Join([]JoinClause{
+		{JoinType: LeftJoin, Lateral: NewBuilder().Select("token").For("secrets").Where([]Condition{Eq("owner_id", Col("people._id"))}), Alias: "s"},
+	}).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unknown table "secrets"`)
+
+	// a lateral alias does not make unqualified columns valid
+	_, _, err = NewBuilder(WithSchema(schema)).Select("password").For("people").Join([]JoinClause{
+		{JoinType: LeftJoin, Lateral: NewBuilder().Select("name").For("teams").Where([]Condition{Eq("id", Col("people.team_id"))}), Alias: "t"},
+	}).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unknown column "password"`)
+
+	_, _, err = NewBuilder(WithSchema(schema)).Select("email").For("people").Union(NewBuilder().Select("token").For("secrets")).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `unknown table "secrets"`)
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// function parsing the text of a full text search
+type TSQueryMode string
+
+const (
+	PlainQuery     TSQueryMode = "plainto_tsquery"      // words are and-ed, e.g. fat rats
+	WebSearchQuery TSQueryMode = "websearch_to_tsquery" // search engine syntax, e.g. "fat rats" or cats -dogs
+	PhraseQuery    TSQueryMode = "phraseto_tsquery"     // words must follow each other
+	RawQuery       TSQueryMode = "to_tsquery"           // tsquery syntax, e.g. fat & (rat | cat)
+)
+
+// a full text search of a document column
+type TextSearch struct {
+	Field    string // tsvector column, or a text column with ToVector
+	ToVector bool   // wrap the field in to_tsvector
+	Query    string // text of the search, bound as a parameter
+	Mode     TSQueryMode
+	Config   string // text search configuration, defaults to WithTextSearchConfig
+}
+
+// field @@ query, the document matches the search
+//
+//	Where([]Condition{Matches(TextSearch{Field: "body", ToVector: true, Query: q, Mode: WebSearchQuery})})
+func Matches(search TextSearch) Condition {
+	return Condition{Type: ConditionTextSearch, Values: []any{search}}
+}
+
+// rank of the document for the search, e.g. to order results by relevance
+func TSRank(search TextSearch) TSRankExpr {
+	return TSRankExpr{search: search}
+}
+
+// snippet of the text column with the search terms highlighted, options are
+// the ts_headline options, e.g. "MaxWords=20, MinWords=5"
+func TSHeadline(field string, search TextSearch, options string) TSHeadlineExpr {
+	return TSHeadlineExpr{field: field, search: search, options: options}
+}
+
+type TSRankExpr struct {
+	search TextSearch
+}
+
+// name the result column
+func (r TSRankExpr) As(alias string) Expr {
+	return Alias(r, alias)
+}
+
+type TSHeadlineExpr struct {
+	field   string
+	search  TextSearch
+	options string
+}
+
+// name the result column
+func (h TSHeadlineExpr) As(alias string) Expr {
+	return Alias(h, alias)
+}
+
+// default text search configuration of full text conditions, e.g. "english"
+func WithTextSearchConfig(config string) QueryOption {
+	return func(q *query) {
+		q.tsConfig = config
+	}
+}
+
+// the text search configuration, rendered as a literal so expression indexes
+// over to_tsvector('english', body) apply
+func (q *query) textSearchConfig(search TextSearch) (string, error) {
+	config := search.Config
+	if config == "" {
+		config = q.tsConfig
+	}
+
+	if config == "" {
+		return "", nil
+	}
+
+	if !identRe.MatchString(config) || strings.HasSuffix(config, "*") {
+		return "", fmt.Errorf("invalid text search config %q", config)
+	}
+
+	lit, err := quoteLiteral(config)
+	if err != nil {
+		return "", err
+	}
+	return lit + ", ", nil
+}
+
+// render the document vector of the search
+func (q *query) buildTSVector(search TextSearch, config string) (string, error) {
+	field, err := q.fieldIdent(search.Field)
+	if err != nil {
+		return "", err
+	}
+
+	if search.ToVector {
+		return fmt.Sprintf("to_tsvector(%s%s)", config, field), nil
+	}
+	return field, nil
+}
+
+// render the tsquery of the search, binding its text
+func (q *query) buildTSQuery(search TextSearch, config string) (string, error) {
+	mode := search.Mode
+	if mode == "" {
+		mode = PlainQuery
+	}
+
+	switch mode {
+	case PlainQuery, WebSearchQuery, PhraseQuery, RawQuery:
+	default:
+		return "", fmt.Errorf("invalid text search mode %q", search.Mode)
+	}
+
+	ph, err := q.bindValue(search.Query)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s(%s%s)", mode, config, ph), nil
+}
+
+// build a full text match, e.g. to_tsvector('english', body) @@ websearch_to_tsquery('english', $1)
+func (q *query) buildTextSearchClause(cond Condition) (string, error) {
+	if len(cond.Values) == 0 {
+		return "", fmt.Errorf("text search condition missing search")
+	}
+
+	search, ok := cond.Values[0].(TextSearch)
+	if !ok {
+		return "", fmt.Errorf("text search condition requires a TextSearch")
+	}
+
+	config, err := q.textSearchConfig(search)
+	if err != nil {
+		return "", err
+	}
+
+	vector, err := q.buildTSVector(search, config)
+	if err != nil {
+		return "", err
+	}
+
+	tsQuery, err := q.buildTSQuery(search, config)
+	if err != nil {
+		return "", err
+	}
+
+	notStr := ""
+	if cond.Not {
+		notStr = "NOT "
+	}
+
+	return fmt.Sprintf("%s%s @@ %s", notStr, vector, tsQuery), nil
+}
+
+func (r TSRankExpr) buildExpr(q *query) (string, error) {
+	config, err := q.textSearchConfig(r.search)
+	if err != nil {
+		return "", err
+	}
+
+	vector, err := q.buildTSVector(r.search, config)
+	if err != nil {
+		return "", err
+	}
+
+	tsQuery, err := q.buildTSQuery(r.search, config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ts_rank(%s, %s)", vector, tsQuery), nil
+}
+
+func (h TSHeadlineExpr) buildExpr(q *query) (string, error) {
+	config, err := q.textSearchConfig(h.search)
+	if err != nil {
+		return "", err
+	}
+
+	field, err := q.fieldIdent(h.field)
+	if err != nil {
+		return "", err
+	}
+
+	tsQuery, err := q.buildTSQuery(h.search, config)
+	if err != nil {
+		return "", err
+	}
+
+	if h.options == "" {
+		return fmt.Sprintf("ts_headline(%s%s, %s)", config, field, tsQuery), nil
+	}
+
+	options, err := q.bindValue(h.options)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ts_headline(%s%s, %s, %s)", config, field, tsQuery, options), nil
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextSearch(t *testing.T) {
+	search := TextSearch{Field: "body", ToVector: true, Query: `"rolling restart" -staging`, Mode: WebSearchQuery}
+
+	// the default config applies to every part of the search
+	sqlStr, args, err := NewBuilder(WithTextSearchConfig("english")).Select("_id").SelectExpr(
+		TSRank(search).As("rank"),
+		TSHeadline("body", search, "MaxWords=20, MinWords=5").As("snippet"),
+	).For("runbooks").Where([]Condition{Matches(search)}).OrderBy([]OrderClause{{Field: "rank", Order: Desc}}).Limit(10).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id, ts_rank(to_tsvector('english', body), websearch_to_tsquery('english', $1)) AS rank, ts_headline('english', body, websearch_to_tsquery('english', $2), $3) AS snippet FROM runbooks WHERE to_tsvector('english', body) @@ websearch_to_tsquery('english', $4) ORDER BY rank DESC LIMIT $5;`, sqlStr)
+	assert.Equal(t, []any{`"rolling restart" -staging`, `"rolling restart" -staging`, "MaxWords=20, MinWords=5", `"rolling restart" -staging`, 10}, args)
+
+	// tsvector column, server default config and ordering by rank expression
+	indexed := TextSearch{Field: "search_vector", Query: "disk full"}
+
+	sqlStr, args, err = NewBuilder().Select("_id").For("runbooks").Where([]Condition{
+		And(
+			Eq("team", "ops"),
+			Not(Matches(TextSearch{Field: "search_vector", Query: "deprecated", Mode: PhraseQuery, Config: "simple"})),
+		),
+	}).OrderBy([]OrderClause{{Expr: TSRank(indexed), Order: Desc}}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM runbooks WHERE (  team = $1 AND NOT search_vector @@ phraseto_tsquery('simple', $2) ) ORDER BY ts_rank(search_vector, plainto_tsquery($3)) DESC;`, sqlStr)
+	assert.Equal(t, []any{"ops", "deprecated", "disk full"}, args)
+
+	// failure cases
+	invalid := []TextSearch{
+		{Field: "body", Query: "x", Mode: "to_tsquery('english', 'x') OR 1=1 --"},
+		{Field: "body", Query: "x", Config: "english') --"},
+		{Field: "body; DROP TABLE runbooks", Query: "x"},
+	}
+
+	for _, search := range invalid {
+		_, _, err = NewBuilder().Select().For("runbooks").Where([]Condition{Matches(search)}).ToSQL()
+		assert.NotNil(t, err, "%+v", search)
+	}
+
+	_, _, err = NewBuilder().Select().For("runbooks").Where([]Condition{
+		{Type: ConditionTextSearch, Values: []any{"disk full"}},
+	}).ToSQL()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"fmt"
+)
+
+// a select combined with the query
+type setOperation struct {
+	op    string
+	query QueryExecutor
+}
+
+// combine the rows of the queries, dropping duplicates. Order by, limit and
+// offset of the query apply to the combined rows.
+//
+//	q.Select("email").For("people").Union(NewBuilder().Select("email").For("guests")).OrderBy(...)
+func (q *query) Union(queries ...QueryExecutor) QueryExecutor {
+	return q.combine("UNION", queries)
+}
+
+// combine the rows of the queries, keeping duplicates
+func (q *query) UnionAll(queries ...QueryExecutor) QueryExecutor {
+	return q.combine("UNION ALL", queries)
+}
+
+// keep the rows returned by every query
+func (q *query) Intersect(queries ...QueryExecutor) QueryExecutor {
+	return q.combine("INTERSECT", queries)
+}
+
+// remove the rows returned by the queries
+func (q *query) Except(queries ...QueryExecutor) QueryExecutor {
+	return q.combine("EXCEPT", queries)
+}
+
+func (q *query) combine(op string, queries []QueryExecutor) QueryExecutor {
+	q = q.clone()
+
+	if len(queries) == 0 {
+		q.errors = append(q.errors, fmt.Errorf("%s requires a query", op))
+	}
+
+	for _, sub := range queries {
+		q.setOps = append(q.setOps, setOperation{op: op, query: sub})
+	}
+	return q
+}
+
+// render the combined selects, placeholders continue the numbering of the
+// query. Operations apply left to right in the order they were chained, the
+// rows combined so far are grouped when the operator changes since Postgres
+// evaluates INTERSECT first. start is where the select follows its common tables.
+func (q *query) addSetOperations(start int) error {
+	for i, set := range q.setOps {
+		sq, ok := set.query.(*query)
+		if !ok || sq == nil {
+			return fmt.Errorf("unsupported set operation query")
+		}
+
+		if sq.kind != SelectStatement {
+			return fmt.Errorf("%s requires a select query", set.op)
+		}
+
+		sub, err := q.buildSubQuery(sq)
+		if err != nil {
+			return err
+		}
+
+		// a query with its own common tables, order, limits or set operations is grouped
+		if len(sq.ctes) > 0 || len(sq.orderBy) > 0 || sq.limit != nil || sq.offset != nil || len(sq.setOps) > 0 {
+			sub = "(" + sub + ")"
+		}
+
+		if i > 0 && set.op != q.setOps[i-1].op {
+			q.queryString = q.queryString[:start] + "(" + q.queryString[start:] + ")"
+		}
+
+		q.queryString += fmt.Sprintf(" %s %s", set.op, sub)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOperations(t *testing.T) {
+	staff := NewBuilder().Select("email").For("people").Where([]Condition{Eq("location", "FR")})
+	guests := NewBuilder().Select("email").For("guests").Where([]Condition{Eq("event", "summit")})
+	vendors := NewBuilder().Select("email").For("vendors").Where([]Condition{Eq("active", true)})
+
+	// outer order and limit apply to the combined rows
+	sqlStr, args, err := staff.Union(guests, vendors).OrderBy([]OrderClause{{Field: "email", Order: "ASC"}}).Limit(20).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT email FROM people WHERE  location = $1 UNION SELECT email FROM guests WHERE  event = $2 UNION SELECT email FROM vendors WHERE  active = $3 ORDER BY email ASC LIMIT $4;`, sqlStr)
+	assert.Equal(t, []any{"FR", "summit", true, 20}, args)
+
+	sqlStr, _, err = staff.UnionAll(guests).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT email FROM people WHERE  location = $1 UNION ALL SELECT email FROM guests WHERE  event = $2;`, sqlStr)
+
+	// mixed operators apply in the order they were chained
+	sqlStr, _, err = staff.Intersect(guests).Except(vendors).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `(SELECT email FROM people WHERE  location = $1 INTERSECT SELECT email FROM guests WHERE  event = $2) EXCEPT SELECT email FROM vendors WHERE  active = $3;`, sqlStr)
+
+	sqlStr, _, err = staff.Union(guests).Intersect(vendors).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `(SELECT email FROM people WHERE  location = $1 UNION SELECT email FROM guests WHERE  event = $2) INTERSECT SELECT email FROM vendors WHERE  active = $3;`, sqlStr)
+
+	sqlStr, _, err = NewBuilder().With("fr", staff).Select("email").For("fr").Union(guests).UnionAll(vendors).Union(guests).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `WITH fr AS (SELECT email FROM people WHERE  location = $1) ((SELECT email FROM fr UNION SELECT email FROM guests WHERE  event = $2) UNION ALL SELECT email FROM vendors WHERE  active = $3) UNION SELECT email FROM guests WHERE  event = $4;`, sqlStr)
+
+	// components with their own limits are grouped
+	sqlStr, _, err = staff.Union(guests.OrderBy([]OrderClause{{Field: "email", Order: "DESC"}}).Limit(5)).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT email FROM people WHERE  location = $1 UNION (SELECT email FROM guests WHERE  event = $2 ORDER BY email DESC LIMIT $3);`, sqlStr)
+
+	// components with their own common tables are grouped
+	sqlStr, _, err = staff.Union(NewBuilder().With("g", guests).Select("email").For("g")).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT email FROM people WHERE  location = $1 UNION (WITH g AS (SELECT email FROM guests WHERE  event = $2) SELECT email FROM g);`, sqlStr)
+
+	// the components are left untouched
+	sqlStr, _, err = staff.ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT email FROM people WHERE  location = $1;`, sqlStr)
+
+	// failure cases
+	_, _, err = staff.Union().ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = staff.Except(NewBuilder().For("guests").Where([]Condition{Eq("_id", 1)}).As(DeleteStatement)).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "requires a select query")
+
+	_, err = staff.Union(guests).OrderBy([]OrderClause{{Field: "email", Order: "ASC"}}).Paginate("", 10)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// kind of statement rendered by ToSQL
+type StatementKind int
+
+const (
+	SelectStatement StatementKind = iota
+	InsertStatement
+	UpdateStatement
+	DeleteStatement
+)
+
+// set the statement kind rendered by ToSQL, defaults to select
+func (q *query) As(kind StatementKind) QueryExecutor {
+	q = q.clone()
+
+	if kind < SelectStatement || kind > DeleteStatement {
+		q.errors = append(q.errors, fmt.Errorf("invalid statement kind %d", kind))
+	}
+
+	q.kind = kind
+	return q
+}
+
+// build the statement of the given kind, without the terminating semicolon
+func (q *query) build(kind StatementKind) error {
+	switch kind {
+	case InsertStatement:
+		return q.buildInsert()
+	case UpdateStatement:
+		return q.buildUpdate()
+	case DeleteStatement:
+		return q.buildDelete()
+	default:
+		return q.buildSelect()
+	}
+}
+
+// renders the statement and its arguments, the query itself is left untouched
+// and no database connection is required
+func (q *query) ToSQL() (string, []any, error) {
+	c := q.fresh()
+
+	err := c.build(c.kind)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return c.queryString + ";", c.args, nil
+}
+
+// get the db instance, builders without a connection cannot execute
+func (q *query) db() (*sql.DB, error) {
+	if q.conn == nil || q.conn.GetDB() == nil {
+		return nil, fmt.Errorf("could not find connection")
+	}
+
+	return q.conn.GetDB(), nil
+}
+
+// constructor for a query builder without a connection, it can only render sql
+func NewBuilder(opts ...QueryOption) QueryExecutor {
+	q := &query{}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	q.errors = make([]error, 0)
+	return q
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSQL(t *testing.T) {
+	// select
+	sqlStr, args, err := NewBuilder().Select("_id", "email").For("people").Where([]Condition{
+		Eq("location", "FR"),
+	}).OrderBy([]OrderClause{{Field: "email", Order: "ASC"}}).Limit(10).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id, email FROM people WHERE  location = $1 ORDER BY email ASC LIMIT $2;`, sqlStr)
+	assert.Equal(t, []any{"FR", 10}, args)
+
+	// insert
+	sqlStr, args, err = NewBuilder().Set(map[string]any{"_id": "india", "description": "Team 99"}).For("fire_teams").As(InsertStatement).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `INSERT INTO fire_teams (_id, description) VALUES ($1, $2);`, sqlStr)
+	assert.Equal(t, []any{"india", "Team 99"}, args)
+
+	// update
+	sqlStr, args, err = NewBuilder().Set(map[string]any{"description": "Team 666"}).For("fire_teams").Where([]Condition{
+		Eq("_id", "foxtrot"),
+	}).As(UpdateStatement).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `UPDATE fire_teams SET description = $1 WHERE  _id = $2;`, sqlStr)
+	assert.Equal(t, []any{"Team 666", "foxtrot"}, args)
+
+	// delete
+	sqlStr, args, err = NewBuilder().For("fire_teams").Where([]Condition{
+		In("_id", "foxtrot", "golf"),
+	}).As(DeleteStatement).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `DELETE FROM fire_teams WHERE _id  IN ($1, $2);`, sqlStr)
+	assert.Equal(t, []any{"foxtrot", "golf"}, args)
+
+	// build errors are reported
+	_, _, err = NewBuilder().For("fire_teams").As(DeleteStatement).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "where clause required for delete")
+}
+
+func TestBuilderWithoutConnection(t *testing.T) {
+	q := NewBuilder().Select().For("people")
+
+	sqlStr, _, err := q.ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT * FROM people;`, sqlStr)
+
+	// rendering again yields the same statement
+	again, _, err := q.ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, sqlStr, again)
+
+	_, err = q.Find()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "could not find connection")
+
+	_, err = NewBuilder().Set(map[string]any{"_id": "india"}).For("fire_teams").Create()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "could not find connection")
+}
+
+func TestLimitOffset(t *testing.T) {
+	base := NewBuilder().Select("_id").For("people").OrderBy([]OrderClause{{Field: "score", Order: Desc}})
+
+	// bound limits render the same statement for every page
+	sqlStr, args, err := base.Limit(20).Offset(40).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people ORDER BY score DESC LIMIT $1 OFFSET $2;`, sqlStr)
+	assert.Equal(t, []any{20, 40}, args)
+
+	// a zero limit returns no rows
+	sqlStr, args, err = base.Limit(0).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people ORDER BY score DESC LIMIT $1;`, sqlStr)
+	assert.Equal(t, []any{0}, args)
+
+	// unset
+	sqlStr, _, err = base.Limit(20).Offset(40).NoLimit().NoOffset().ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people ORDER BY score DESC;`, sqlStr)
+
+	// ties with the last row
+	sqlStr, args, err = base.LimitWithTies(3).Offset(6).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people ORDER BY score DESC OFFSET $1 ROWS FETCH FIRST $2 ROWS WITH TIES;`, sqlStr)
+	assert.Equal(t, []any{6, 3}, args)
+
+	sqlStr, _, err = base.LimitWithTies(3).Limit(3).ToSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id FROM people ORDER BY score DESC LIMIT $1;`, sqlStr)
+
+	// failure cases
+	_, _, err = base.Limit(-1).ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = base.Offset(-1).ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().Select().For("people").LimitWithTies(3).ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "requires order by")
+}
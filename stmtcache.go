@@ -0,0 +1,218 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+)
+
+// default number of prepared statements kept per connection
+const DefaultStmtCacheSize = 100
+
+// StmtCache is a per connection LRU cache of prepared statements keyed by
+// their sql text. Evicted statements are closed once no execution uses them.
+type StmtCache struct {
+	mu    sync.Mutex
+	db    *sql.DB
+	size  int
+	lru   *list.List // front is the most recently used
+	items map[string]*list.Element
+	stats StmtCacheStats
+}
+
+// hit / miss metrics of a statement cache
+type StmtCacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Evictions     uint64
+	Invalidations uint64
+	Len           int
+}
+
+type cachedStmt struct {
+	sql     string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// connections which cache their prepared statements
+type StmtCacher interface {
+	StmtCache() *StmtCache
+}
+
+// constructor for the statement cache, a size <= 0 disables caching
+func NewStmtCache(db *sql.DB, size int) *StmtCache {
+	return &StmtCache{
+		db:    db,
+		size:  size,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get a prepared statement for the sql, preparing it on a miss
+func (c *StmtCache) acquire(sqlStr string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if el, ok := c.items[sqlStr]; ok {
+		c.lru.MoveToFront(el)
+		entry := el.Value.(*cachedStmt)
+		entry.refs++
+		c.stats.Hits++
+		c.mu.Unlock()
+		return entry, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// prepare without holding the lock
+	stmt, err := c.db.Prepare(sqlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// another execution may have cached the same statement meanwhile
+	if el, ok := c.items[sqlStr]; ok {
+		stmt.Close()
+		c.lru.MoveToFront(el)
+		entry := el.Value.(*cachedStmt)
+		entry.refs++
+		return entry, nil
+	}
+
+	entry := &cachedStmt{sql: sqlStr, stmt: stmt, refs: 1}
+	if c.size <= 0 {
+		// caching disabled, closed on release
+		entry.evicted = true
+		return entry, nil
+	}
+
+	c.items[sqlStr] = c.lru.PushFront(entry)
+	c.evict()
+
+	return entry, nil
+}
+
+// release a statement, closing it if it was evicted while in use
+func (c *StmtCache) release(entry *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// remove an entry, the caller must hold the lock
+func (c *StmtCache) remove(el *list.Element) {
+	entry := el.Value.(*cachedStmt)
+	c.lru.Remove(el)
+	delete(c.items, entry.sql)
+
+	entry.evicted = true
+	if entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// drop least recently used statements over the size limit, the caller must hold the lock
+func (c *StmtCache) evict() {
+	for c.lru.Len() > c.size && c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// drop the cached statement for the sql, e.g. after a schema change
+func (c *StmtCache) Invalidate(sqlStr string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[sqlStr]; ok {
+		c.remove(el)
+		c.stats.Invalidations++
+	}
+}
+
+// change the size limit, evicting statements over the new limit
+func (c *StmtCache) Resize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.size = size
+	c.evict()
+}
+
+// current metrics of the cache
+func (c *StmtCache) Stats() StmtCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Len = c.lru.Len()
+	return stats
+}
+
+// close every cached statement
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+	}
+	return nil
+}
+
+// postgres reports this when a prepared statement outlives a schema change
+func isStalePlanError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "cached plan must not change result type")
+}
+
+// execute the sql through a cached prepared statement, a statement invalidated
+// by a schema change is prepared again once
+func (c *StmtCache) Exec(sqlStr string, args ...any) (sql.Result, error) {
+	return c.ExecContext(context.Background(), sqlStr, args...)
+}
+
+// Exec with a context
+func (c *StmtCache) ExecContext(ctx context.Context, sqlStr string, args ...any) (sql.Result, error) {
+	res, err := c.exec(ctx, sqlStr, args)
+	if isStalePlanError(err) {
+		c.Invalidate(sqlStr)
+		res, err = c.exec(ctx, sqlStr, args)
+	}
+	return res, err
+}
+
+func (c *StmtCache) exec(ctx context.Context, sqlStr string, args []any) (sql.Result, error) {
+	entry, err := c.acquire(sqlStr)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(entry)
+
+	return entry.stmt.ExecContext(ctx, args...)
+}
+
+// run an insert / update / delete statement, through the statement cache of
+// the connection when it has one
+func execStatement(ctx context.Context, conn ConnectionExecutor, queryString string, args []any) (sql.Result, error) {
+	if cacher, ok := conn.(StmtCacher); ok && cacher.StmtCache() != nil {
+		return cacher.StmtCache().ExecContext(ctx, queryString, args...)
+	}
+
+	stmt, err := conn.GetDB().PrepareContext(ctx, queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	return stmt.ExecContext(ctx, args...)
+}
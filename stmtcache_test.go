@@ -0,0 +1,113 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// mock connection which caches its prepared statements
+type CachedMockConnection struct {
+	MockConnection
+	cache *StmtCache
+}
+
+func (m *CachedMockConnection) StmtCache() *StmtCache {
+	return m.cache
+}
+
+func newCachedMockConnection(t *testing.T, size int) *CachedMockConnection {
+	conn := &CachedMockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	conn.cache = NewStmtCache(conn.GetDB(), size)
+	return conn
+}
+
+func TestStmtCacheHits(t *testing.T) {
+	conn := newCachedMockConnection(t, 10)
+	defer conn.Close()
+
+	mock := conn.GetMock()
+
+	updateStr := `UPDATE fire_teams SET description = $1 WHERE  _id = $2;`
+
+	// prepared once, executed twice
+	mock.ExpectPrepare(updateStr)
+	mock.ExpectExec(updateStr).WithArgs(`Team 1`, `alpha`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(updateStr).WithArgs(`Team 2`, `bravo`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	for i, id := range []string{"alpha", "bravo"} {
+		q, err := NewQuery(conn)
+		assert.Nil(t, err)
+
+		count, err := q.Set(map[string]any{"description": fmt.Sprintf("Team %d", i+1)}).For("fire_teams").Where([]Condition{Eq("_id", id)}).Update()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	}
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+	assert.Equal(t, StmtCacheStats{Hits: 1, Misses: 1, Len: 1}, conn.StmtCache().Stats())
+}
+
+func TestStmtCacheEviction(t *testing.T) {
+	conn := newCachedMockConnection(t, 1)
+	defer conn.Close()
+
+	mock := conn.GetMock()
+
+	deleteStr := `DELETE FROM fire_teams WHERE  _id = $1;`
+	insertStr := `INSERT INTO fire_teams (_id) VALUES ($1);`
+
+	// the delete statement is closed when the insert evicts it
+	mock.ExpectPrepare(deleteStr).WillBeClosed()
+	mock.ExpectExec(deleteStr).WithArgs(`alpha`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(insertStr)
+	mock.ExpectExec(insertStr).WithArgs(`alpha`).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	_, err = q.For("fire_teams").Where([]Condition{Eq("_id", "alpha")}).Delete()
+	assert.Nil(t, err)
+
+	_, err = q.Set(map[string]any{"_id": "alpha"}).For("fire_teams").Create()
+	assert.Nil(t, err)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+	assert.Equal(t, StmtCacheStats{Misses: 2, Evictions: 1, Len: 1}, conn.StmtCache().Stats())
+
+	// shrinking the cache closes what no longer fits
+	conn.StmtCache().Resize(0)
+	assert.Equal(t, 0, conn.StmtCache().Stats().Len)
+}
+
+func TestStmtCacheInvalidation(t *testing.T) {
+	conn := newCachedMockConnection(t, 10)
+	defer conn.Close()
+
+	mock := conn.GetMock()
+
+	deleteStr := `DELETE FROM fire_teams WHERE  _id = $1;`
+
+	// a stale plan is dropped and the statement prepared again
+	mock.ExpectPrepare(deleteStr).WillBeClosed()
+	mock.ExpectExec(deleteStr).WithArgs(`alpha`).WillReturnError(fmt.Errorf("pq: cached plan must not change result type"))
+	mock.ExpectPrepare(deleteStr)
+	mock.ExpectExec(deleteStr).WithArgs(`alpha`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	count, err := q.For("fire_teams").Where([]Condition{Eq("_id", "alpha")}).Delete()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+	assert.Equal(t, StmtCacheStats{Misses: 2, Invalidations: 1, Len: 1}, conn.StmtCache().Stats())
+}
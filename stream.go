@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+)
+
+// Row is a single result row, one value per selected column
+type Row []any
+
+// set the context used when executing the query, cancelling it stops a
+// running query and closes its rows
+func (q *query) WithContext(ctx context.Context) QueryExecutor {
+	q = q.clone()
+
+	if ctx == nil {
+		q.errors = append(q.errors, fmt.Errorf("nil context not allowed"))
+	}
+
+	q.ctx = ctx
+	return q
+}
+
+// context used when executing, defaults to the background context
+func (q *query) context() context.Context {
+	if q.ctx == nil {
+		return context.Background()
+	}
+	return q.ctx
+}
+
+// scan the current row of the result set, array columns are decoded into slices
+func scanRow(rows *sql.Rows, types []*sql.ColumnType) (Row, error) {
+	vals := make(Row, len(types))
+	scanArgs := make([]any, len(types))
+
+	for i := range vals {
+		scanArgs[i] = &vals[i]
+	}
+
+	if err := rows.Scan(scanArgs...); err != nil {
+		return nil, err
+	}
+
+	for i, t := range types {
+		if vals[i] == nil {
+			continue
+		}
+
+		vals[i] = decodeArray(t.DatabaseTypeName(), vals[i])
+	}
+
+	return vals, nil
+}
+
+// stream the rows of a select statement, the rows stay open until the
+// iteration finishes, breaks or fails
+func streamRows(ctx context.Context, db runner, queryString string, args []any) iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		rows, err := db.QueryContext(ctx, queryString, args...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer rows.Close()
+
+		types, err := rows.ColumnTypes()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for rows.Next() {
+			row, err := scanRow(rows, types)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(row, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// run a select statement and collect its rows
+func queryRows(ctx context.Context, db runner, queryString string, args []any) ([][]any, error) {
+	_, rowData, err := queryColumnRows(ctx, db, queryString, args)
+	return rowData, err
+}
+
+// run a select statement and collect its rows along with the column names
+func queryColumnRows(ctx context.Context, db runner, queryString string, args []any) ([]string, [][]any, error) {
+	rows, err := db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cols := make([]string, len(types))
+	for i, t := range types {
+		cols[i] = t.Name()
+	}
+
+	var rowData [][]any
+	for rows.Next() {
+		row, err := scanRow(rows, types)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rowData = append(rowData, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return cols, rowData, nil
+}
+
+// stream the rows of the select query
+//
+//	for row, err := range q.Select().For("audit").Rows() {
+//		if err != nil { ... }
+//	}
+func (q *query) Rows() iter.Seq2[Row, error] {
+	db, queryString, args, err := q.prepareSelect()
+	if err != nil {
+		return func(yield func(Row, error) bool) {
+			yield(nil, err)
+		}
+	}
+
+	return streamRows(q.context(), db, queryString, args)
+}
+
+// call fn for every row of the select query, an error from fn stops the
+// iteration and is returned
+func (q *query) Each(fn func(Row) error) error {
+	for row, err := range q.Rows() {
+		if err != nil {
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestStreamRows(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	queryString := `SELECT _id FROM audit WHERE  actor = $1;`
+	newRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"_id"}).AddRow(`1`).AddRow(`2`).AddRow(`3`)
+	}
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	audit := q.Select("_id").For("audit").Where([]Condition{Eq("actor", "jpomfrette")})
+
+	// every row is streamed
+	mock.ExpectQuery(queryString).WithArgs(`jpomfrette`).WillReturnRows(newRows()).RowsWillBeClosed()
+
+	var ids []any
+	for row, err := range audit.Rows() {
+		assert.Nil(t, err)
+		ids = append(ids, row[0])
+	}
+	assert.Equal(t, []any{"1", "2", "3"}, ids)
+
+	// breaking early closes the rows
+	mock.ExpectQuery(queryString).WithArgs(`jpomfrette`).WillReturnRows(newRows()).RowsWillBeClosed()
+
+	for row, err := range audit.Rows() {
+		assert.Nil(t, err)
+		assert.Equal(t, Row{"1"}, row)
+		break
+	}
+
+	// an error from the callback stops the iteration
+	mock.ExpectQuery(queryString).WithArgs(`jpomfrette`).WillReturnRows(newRows()).RowsWillBeClosed()
+
+	seen := 0
+	err = audit.Each(func(row Row) error {
+		seen++
+		if row[0] == "2" {
+			return fmt.Errorf("stop at %v", row[0])
+		}
+		return nil
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, "stop at 2", err.Error())
+	assert.Equal(t, 2, seen)
+
+	// row errors are yielded
+	mock.ExpectQuery(queryString).WithArgs(`jpomfrette`).WillReturnRows(newRows().RowError(1, fmt.Errorf("connection reset"))).RowsWillBeClosed()
+
+	err = audit.Each(func(row Row) error { return nil })
+	assert.NotNil(t, err)
+	assert.Equal(t, "connection reset", err.Error())
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamCancellation(t *testing.T) {
+	conn := &MockConnection{}
+
+	err := conn.Connect()
+	assert.Nil(t, err, "failed to connect to db instance")
+
+	mock := conn.GetMock()
+
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mock.ExpectQuery(`SELECT _id FROM audit;`).WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"_id"}).AddRow([]driver.Value{"1"}...))
+
+	q, err := NewQuery(conn)
+	assert.Nil(t, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	err = q.Select("_id").For("audit").WithContext(ctx).Each(func(row Row) error { return nil })
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "canceling query")
+
+	// build errors surface through the iterator
+	for _, err := range q.Select("_id").For("").Rows() {
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// runs statements, implemented by *sql.DB and *sql.Tx
+type runner interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// run the query inside the transaction
+func (q *query) InTx(tx *sql.Tx) QueryExecutor {
+	q = q.clone()
+
+	if tx == nil {
+		q.errors = append(q.errors, fmt.Errorf("nil transaction not allowed"))
+	}
+
+	q.tx = tx
+	return q
+}
+
+// the transaction of the query when set, otherwise the db instance
+func (q *query) runner() (runner, error) {
+	if q.tx != nil {
+		return q.tx, nil
+	}
+
+	return q.db()
+}
+
+// execute the built insert / update / delete statement
+func (q *query) exec() (int64, error) {
+	args, err := bindParams(q.args, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var res sql.Result
+	if q.tx != nil {
+		res, err = q.tx.ExecContext(q.context(), q.queryString, args...)
+	} else {
+		// the connection is checked before executing
+		_, err = q.db()
+		if err != nil {
+			return 0, err
+		}
+
+		res, err = execStatement(q.context(), q.conn, q.queryString, args)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -1,22 +1,31 @@
 package database
 
 import (
-	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 
 	"strconv"
 )
 
 func (q *query) Set(values map[string]any) QueryExecutor {
+	q = q.clone()
+
 	l := len(values)
 
 	if l > 0 {
 		q.cols = make([]string, l)
 		q.colValues = make([]any, l)
 
-		i := 0
-		for k, v := range values {
+		// sorted keys keep the generated sql stable
+		keys := make([]string, 0, l)
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for i, k := range keys {
+			v := values[k]
 			if k == "" {
 				q.errors = append(q.errors, fmt.Errorf("Set found empty key at position %v", i))
 			}
@@ -25,7 +34,6 @@ func (q *query) Set(values map[string]any) QueryExecutor {
 			}
 			q.cols[i] = k
 			q.colValues[i] = v
-			i++
 		}
 	}
 
@@ -35,7 +43,7 @@ func (q *query) Set(values map[string]any) QueryExecutor {
 func (q *query) setColumns() error {
 	setCols := make([]string, len(q.cols))
 	for i, v := range q.cols {
-		q.ArgCount++
+		q.argCount++
 
 		if v == "" {
 			nerr := fmt.Errorf("set columns found empty key at position %v", i)
@@ -43,65 +51,85 @@ func (q *query) setColumns() error {
 			return nerr
 		}
 
-		setCols[i] = fmt.Sprintf("%s = $%s", v, strconv.Itoa(q.ArgCount))
+		col, err := q.fieldIdent(v)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return err
+		}
+
+		setCols[i] = fmt.Sprintf("%s = $%s", col, strconv.Itoa(q.argCount))
+	}
+
+	q.queryString += fmt.Sprintf(" SET %s", strings.Join(setCols, ", "))
+	q.args = append(q.args, q.colValues...)
+
+	return nil
+}
+
+func (q *query) BuildUpdateQuery() error {
+	err := q.buildUpdate()
+	if err != nil {
+		return err
 	}
 
-	q.QueryString += fmt.Sprintf(" SET %s", strings.Join(setCols, ", "))
-	q.Args = q.colValues
+	q.queryString += ";"
+
+	fmt.Println("update query => ", q.queryString, q.args)
 
 	return nil
 }
 
-func (q *query) BuildUpdateQuery() (*sql.Stmt, error) {
+// builds the update statement without the terminating semicolon
+func (q *query) buildUpdate() error {
 	err := q.checkPreBuildErrors()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if len(q.cols) != len(q.colValues) {
-		return nil, fmt.Errorf("columns / values length mismatch")
+		return fmt.Errorf("columns / values length mismatch")
+	}
+
+	err = q.addWith()
+	if err != nil {
+		return err
+	}
+
+	table, err := q.tableIdent(q.table)
+	if err != nil {
+		return err
 	}
 
 	// Start building the query
-	q.QueryString = fmt.Sprintf("UPDATE %s", q.table)
+	q.queryString += fmt.Sprintf("UPDATE %s", table)
 
 	// set the columns to be updated
 	err = q.setColumns()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Add WHERE conditions
 	err = q.addWhere()
 	if err != nil {
-		return nil, err
-	}
-
-	q.QueryString += ";"
-
-	fmt.Println("update query => ", q.QueryString, q.Args)
-
-	stmt, err := q.conn.GetDB().Prepare(q.QueryString)
-	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return stmt, nil
+	return q.addReturning()
 }
 
 func (q *query) Update() (int64, error) {
+	q = q.fresh()
 
-	stmt, err := q.BuildUpdateQuery()
+	err := q.checkReturning()
 	if err != nil {
 		return 0, err
 	}
-	defer stmt.Close()
 
-	res, err := stmt.Exec(q.Args...)
+	err = q.BuildUpdateQuery()
 	if err != nil {
 		return 0, err
 	}
 
-	c, err := res.RowsAffected()
-	return c, err
+	return q.exec()
 }
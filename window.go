@@ -0,0 +1,293 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// frame unit of a window
+type FrameMode string
+
+const (
+	RowsFrame   FrameMode = "ROWS"
+	RangeFrame  FrameMode = "RANGE"
+	GroupsFrame FrameMode = "GROUPS"
+)
+
+// start or end of a window frame
+type FrameBoundKind string
+
+const (
+	UnboundedPreceding FrameBoundKind = "UNBOUNDED PRECEDING"
+	Preceding          FrameBoundKind = "PRECEDING" // Offset rows / groups before the current one
+	CurrentRow         FrameBoundKind = "CURRENT ROW"
+	Following          FrameBoundKind = "FOLLOWING" // Offset rows / groups after the current one
+	UnboundedFollowing FrameBoundKind = "UNBOUNDED FOLLOWING"
+)
+
+type FrameBound struct {
+	Kind   FrameBoundKind
+	Offset int
+}
+
+// window frame, e.g. ROWS BETWEEN 6 PRECEDING AND CURRENT ROW. An empty End
+// renders the start bound only.
+type Frame struct {
+	Mode  FrameMode
+	Start FrameBound
+	End   FrameBound
+}
+
+// window definition of a window function or a named WINDOW clause
+type Window struct {
+	PartitionBy []string
+	OrderBy     []OrderClause
+	Frame       *Frame
+}
+
+// a named window of the WINDOW clause
+type namedWindow struct {
+	name   string
+	window Window
+}
+
+// window function call, e.g. ROW_NUMBER() OVER (PARTITION BY team_id ORDER BY score DESC)
+type WindowExpr struct {
+	fn     string
+	fields []string // column arguments
+	params []any    // bound arguments following the columns
+	window *Window
+	name   string // named window, see query.Window
+}
+
+func RowNumber() WindowExpr   { return WindowExpr{fn: "ROW_NUMBER"} }
+func Rank() WindowExpr        { return WindowExpr{fn: "RANK"} }
+func DenseRank() WindowExpr   { return WindowExpr{fn: "DENSE_RANK"} }
+func PercentRank() WindowExpr { return WindowExpr{fn: "PERCENT_RANK"} }
+func CumeDist() WindowExpr    { return WindowExpr{fn: "CUME_DIST"} }
+
+// split the partition in n buckets
+func NTile(n int) WindowExpr {
+	return WindowExpr{fn: "NTILE", params: []any{n}}
+}
+
+// value of the field offset rows before the current one, def is returned
+// when there is no such row
+func Lag(field string, offset int, def ...any) WindowExpr {
+	return WindowExpr{fn: "LAG", fields: []string{field}, params: append([]any{offset}, def...)}
+}
+
+// value of the field offset rows after the current one
+func Lead(field string, offset int, def ...any) WindowExpr {
+	return WindowExpr{fn: "LEAD", fields: []string{field}, params: append([]any{offset}, def...)}
+}
+
+func FirstValue(field string) WindowExpr {
+	return WindowExpr{fn: "FIRST_VALUE", fields: []string{field}}
+}
+
+func LastValue(field string) WindowExpr {
+	return WindowExpr{fn: "LAST_VALUE", fields: []string{field}}
+}
+
+func NthValue(field string, n int) WindowExpr {
+	return WindowExpr{fn: "NTH_VALUE", fields: []string{field}, params: []any{n}}
+}
+
+// aggregate over a window, e.g. WindowAggregate("sum", "amount"), "*" counts rows
+func WindowAggregate(fn, field string) WindowExpr {
+	return WindowExpr{fn: strings.ToUpper(fn), fields: []string{field}}
+}
+
+// evaluate over the window
+func (w WindowExpr) Over(window Window) WindowExpr {
+	w.window, w.name = &window, ""
+	return w
+}
+
+// evaluate over a window defined on the query with Window
+func (w WindowExpr) OverWindow(name string) WindowExpr {
+	w.window, w.name = nil, name
+	return w
+}
+
+// name the result column
+func (w WindowExpr) As(alias string) Expr {
+	return Alias(w, alias)
+}
+
+var windowFuncs = map[string]bool{
+	"ROW_NUMBER":   true,
+	"RANK":         true,
+	"DENSE_RANK":   true,
+	"PERCENT_RANK": true,
+	"CUME_DIST":    true,
+	"NTILE":        true,
+	"LAG":          true,
+	"LEAD":         true,
+	"FIRST_VALUE":  true,
+	"LAST_VALUE":   true,
+	"NTH_VALUE":    true,
+}
+
+func (w WindowExpr) buildExpr(q *query) (string, error) {
+	if !windowFuncs[w.fn] && !aggregateFuncs[strings.ToLower(w.fn)] {
+		return "", fmt.Errorf("invalid window function %q", w.fn)
+	}
+
+	var fields string
+	var err error
+	if len(w.fields) == 1 && w.fields[0] == "*" {
+		fields = "*"
+	} else {
+		fields, err = q.fieldList(w.fields)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	params, err := q.valueList(w.params)
+	if err != nil {
+		return "", err
+	}
+
+	call := fmt.Sprintf("%s(%s)", w.fn, joinParts(fields, params))
+
+	switch {
+	case w.window != nil:
+		spec, err := q.buildWindow(*w.window)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s OVER (%s)", call, spec), nil
+	case w.name != "":
+		if !q.hasWindow(w.name) {
+			return "", fmt.Errorf("unknown window %q", w.name)
+		}
+
+		name, err := q.aliasIdent(w.name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s OVER %s", call, name), nil
+	default:
+		return "", fmt.Errorf("window function %s requires a window", w.fn)
+	}
+}
+
+// define a named window, window functions refer to it with OverWindow
+func (q *query) Window(name string, window Window) QueryExecutor {
+	q = q.clone()
+
+	if q.hasWindow(name) {
+		q.errors = append(q.errors, fmt.Errorf("window %s defined twice", name))
+	}
+
+	q.windows = append(q.windows, namedWindow{name: name, window: window})
+	return q
+}
+
+func (q *query) hasWindow(name string) bool {
+	for _, w := range q.windows {
+		if w.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// render a frame bound
+func frameBound(b FrameBound) (string, error) {
+	switch b.Kind {
+	case UnboundedPreceding, CurrentRow, UnboundedFollowing:
+		return string(b.Kind), nil
+	case Preceding, Following:
+		if b.Offset < 0 {
+			return "", fmt.Errorf("invalid frame offset %d", b.Offset)
+		}
+		return fmt.Sprintf("%d %s", b.Offset, b.Kind), nil
+	default:
+		return "", fmt.Errorf("invalid frame bound %q", b.Kind)
+	}
+}
+
+// render a window definition, without the parentheses
+func (q *query) buildWindow(w Window) (string, error) {
+	var parts []string
+
+	if len(w.PartitionBy) > 0 {
+		fields, err := q.fieldList(w.PartitionBy)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "PARTITION BY "+fields)
+	}
+
+	if len(w.OrderBy) > 0 {
+		terms := make([]string, len(w.OrderBy))
+		for i, order := range w.OrderBy {
+			if order.Position != 0 {
+				return "", fmt.Errorf("window order by cannot use a position")
+			}
+
+			var err error
+			terms[i], err = q.orderTerm(order)
+			if err != nil {
+				return "", err
+			}
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(terms, ", "))
+	}
+
+	if w.Frame != nil {
+		switch w.Frame.Mode {
+		case RowsFrame, RangeFrame, GroupsFrame:
+		default:
+			return "", fmt.Errorf("invalid frame mode %q", w.Frame.Mode)
+		}
+
+		start, err := frameBound(w.Frame.Start)
+		if err != nil {
+			return "", err
+		}
+
+		if w.Frame.End.Kind == "" {
+			parts = append(parts, fmt.Sprintf("%s %s", w.Frame.Mode, start))
+		} else {
+			end, err := frameBound(w.Frame.End)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, fmt.Sprintf("%s BETWEEN %s AND %s", w.Frame.Mode, start, end))
+		}
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// render the WINDOW clause
+func (q *query) addWindows() error {
+	if len(q.windows) == 0 {
+		return nil
+	}
+
+	defs := make([]string, len(q.windows))
+	for i, w := range q.windows {
+		name, err := q.aliasIdent(w.name)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return err
+		}
+
+		spec, err := q.buildWindow(w.window)
+		if err != nil {
+			q.errors = append(q.errors, err)
+			return err
+		}
+
+		defs[i] = fmt.Sprintf("%s AS (%s)", name, spec)
+	}
+
+	q.queryString += " WINDOW " + strings.Join(defs, ", ")
+	return nil
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowFunctions(t *testing.T) {
+	byTeam := Window{PartitionBy: []string{"team_id"}, OrderBy: []OrderClause{{Field: "score", Order: "DESC"}}}
+
+	// inline windows, aliases may be ordered on
+	sqlStr, args, err := NewBuilder().Select("_id").SelectExpr(
+		RowNumber().Over(byTeam).As("position"),
+		Lag("score", 1, 0).Over(byTeam).As("previous"),
+		WindowAggregate("sum", "score").Over(Window{
+			PartitionBy: []string{"team_id"},
+			OrderBy:     []OrderClause{{Field: "played_at", Order: "ASC"}},
+			Frame:       &Frame{Mode: RowsFrame, Start: FrameBound{Kind: Preceding, Offset: 6}, End: FrameBound{Kind: CurrentRow}},
+		}).As("weekly"),
+	).For("scores").Where([]Condition{Gt("score", 10)}).OrderBy([]OrderClause{{Field: "position", Order: "ASC"}}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT _id, ROW_NUMBER() OVER (PARTITION BY team_id ORDER BY score DESC) AS position, LAG(score, $1, $2) OVER (PARTITION BY team_id ORDER BY score DESC) AS previous, SUM(score) OVER (PARTITION BY team_id ORDER BY played_at ASC ROWS BETWEEN 6 PRECEDING AND CURRENT ROW) AS weekly FROM scores WHERE  score > $3 ORDER BY position ASC;`, sqlStr)
+	assert.Equal(t, []any{1, 0, 10}, args)
+
+	// named windows render between having and order by
+	sqlStr, _, err = NewBuilder().Select("team_id").SelectExpr(Rank().OverWindow("w").As("rank"), WindowAggregate("count", "*").OverWindow("w")).
+		For("scores").Window("w", byTeam).GroupBy([]string{"team_id", "score"}).
+		Having([]Condition{Gt("COUNT(*)", 1)}).OrderBy([]OrderClause{{Field: "team_id", Order: "ASC"}}).ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT team_id, RANK() OVER w AS rank, COUNT(*) OVER w FROM scores GROUP BY team_id, score HAVING  COUNT(*) > $1 WINDOW w AS (PARTITION BY team_id ORDER BY score DESC) ORDER BY team_id ASC;`, sqlStr)
+
+	// aliased columns and frames without an end
+	sqlStr, _, err = NewBuilder().SelectExpr(Alias(FirstValue("_id").Over(Window{
+		OrderBy: []OrderClause{{Field: "score", Order: "DESC"}},
+		Frame:   &Frame{Mode: RangeFrame, Start: FrameBound{Kind: UnboundedPreceding}},
+	}), "best")).For("scores").ToSQL()
+
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT FIRST_VALUE(_id) OVER (ORDER BY score DESC RANGE UNBOUNDED PRECEDING) AS best FROM scores;`, sqlStr)
+
+	// failure cases
+	_, _, err = NewBuilder().SelectExpr(RowNumber()).For("scores").ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "requires a window")
+
+	_, _, err = NewBuilder().SelectExpr(RowNumber().OverWindow("missing")).For("scores").ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown window")
+
+	_, _, err = NewBuilder().SelectExpr(WindowAggregate("pg_sleep", "score").Over(byTeam)).For("scores").ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().SelectExpr(RowNumber().Over(Window{Frame: &Frame{Mode: "ROWS; DROP", Start: FrameBound{Kind: CurrentRow}}})).For("scores").ToSQL()
+	assert.NotNil(t, err)
+
+	_, _, err = NewBuilder().SelectExpr(nil).For("scores").ToSQL()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "nil select expression")
+}